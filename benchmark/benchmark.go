@@ -0,0 +1,172 @@
+// Package benchmark summarizes the builds of a site run several times with
+// each hugo version: their wall time, memory and template metrics.
+package benchmark
+
+import (
+	"bufio"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Run is a build of the site.
+type Run struct {
+	Duration time.Duration `json:"duration"`
+	// PeakRSS is the maximum resident set size of hugo in bytes, 0 when unknown.
+	PeakRSS int64 `json:"peak_rss"`
+	// Templates is the cumulative duration of each template, given by --templateMetrics.
+	Templates map[string]time.Duration `json:"templates,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// Stats summarizes durations.
+type Stats struct {
+	Mean   time.Duration `json:"mean"`
+	Median time.Duration `json:"median"`
+	Stddev time.Duration `json:"stddev"`
+}
+
+// Result is the benchmark of a version.
+type Result struct {
+	Spec     string `json:"spec"`
+	Version  string `json:"version"`
+	Runs     []Run  `json:"runs"`
+	WallTime Stats  `json:"wall_time"`
+	PeakRSS  int64  `json:"peak_rss"`
+	// Templates is the mean cumulative duration of each template over the runs.
+	Templates map[string]time.Duration `json:"templates,omitempty"`
+	Failures  int                      `json:"failures"`
+}
+
+// Report is the benchmark of several versions, saved to be compared over time.
+type Report struct {
+	Date    time.Time `json:"date"`
+	Args    []string  `json:"args"`
+	Results []*Result `json:"results"`
+}
+
+// Summarize computes the statistics of the successful runs of result.
+func (result *Result) Summarize() {
+	durations := []time.Duration{}
+	totals := map[string]time.Duration{}
+	result.Failures, result.PeakRSS = 0, 0
+	for _, run := range result.Runs {
+		if run.Error != "" {
+			result.Failures++
+			continue
+		}
+		durations = append(durations, run.Duration)
+		if run.PeakRSS > result.PeakRSS {
+			result.PeakRSS = run.PeakRSS
+		}
+		for template, duration := range run.Templates {
+			totals[template] += duration
+		}
+	}
+	result.WallTime = Summarize(durations)
+	result.Templates = nil
+	if len(totals) > 0 {
+		result.Templates = map[string]time.Duration{}
+		for template, total := range totals {
+			result.Templates[template] = total / time.Duration(len(durations))
+		}
+	}
+}
+
+// Summarize returns the mean, median and population standard deviation of durations.
+func Summarize(durations []time.Duration) Stats {
+	if len(durations) == 0 {
+		return Stats{}
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum float64
+	for _, duration := range sorted {
+		sum += float64(duration)
+	}
+	mean := sum / float64(len(sorted))
+	var variance float64
+	for _, duration := range sorted {
+		variance += (float64(duration) - mean) * (float64(duration) - mean)
+	}
+	variance /= float64(len(sorted))
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	return Stats{Mean: time.Duration(mean), Median: median, Stddev: time.Duration(math.Sqrt(variance))}
+}
+
+// ParseTemplateMetrics reads the cumulative duration of each template from the
+// table printed by hugo with --templateMetrics, whose first column is the
+// cumulative duration and last column the template.
+func ParseTemplateMetrics(output string) map[string]time.Duration {
+	templates := map[string]time.Duration{}
+	inTable := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "---") {
+			inTable = true
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		if len(fields) < 3 {
+			inTable = false
+			continue
+		}
+		duration, err := time.ParseDuration(fields[0])
+		if err != nil {
+			inTable = false
+			continue
+		}
+		templates[fields[len(fields)-1]] += duration
+	}
+	return templates
+}
+
+// Hotspot is the change of the mean cumulative duration of a template between two versions.
+type Hotspot struct {
+	Template string        `json:"template"`
+	Before   time.Duration `json:"before"`
+	After    time.Duration `json:"after"`
+}
+
+func (hotspot Hotspot) Delta() time.Duration {
+	return hotspot.After - hotspot.Before
+}
+
+// Hotspots returns the templates whose duration changed the most from before
+// to after, at most limit of them.
+func Hotspots(before *Result, after *Result, limit int) []Hotspot {
+	hotspots := []Hotspot{}
+	for template, duration := range after.Templates {
+		hotspots = append(hotspots, Hotspot{Template: template, Before: before.Templates[template], After: duration})
+	}
+	for template, duration := range before.Templates {
+		if _, found := after.Templates[template]; !found {
+			hotspots = append(hotspots, Hotspot{Template: template, Before: duration})
+		}
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		deltaI, deltaJ := absolute(hotspots[i].Delta()), absolute(hotspots[j].Delta())
+		if deltaI != deltaJ {
+			return deltaI > deltaJ
+		}
+		return hotspots[i].Template < hotspots[j].Template
+	})
+	if len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+	return hotspots
+}
+
+func absolute(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return -duration
+	}
+	return duration
+}
@@ -0,0 +1,59 @@
+package benchmark
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := Summarize([]time.Duration{4 * time.Second, 2 * time.Second, 6 * time.Second, 4 * time.Second})
+	assert.Equal(Stats{Mean: 4 * time.Second, Median: 4 * time.Second, Stddev: 1414213562}, stats)
+	assert.Equal(3*time.Second, Summarize([]time.Duration{time.Second, 3 * time.Second, 9 * time.Second}).Median)
+	assert.Equal(Stats{}, Summarize(nil))
+
+	result := &Result{Runs: []Run{
+		{Duration: time.Second, PeakRSS: 100, Templates: map[string]time.Duration{"_default/single.html": 200 * time.Millisecond}},
+		{Duration: 3 * time.Second, PeakRSS: 300, Templates: map[string]time.Duration{"_default/single.html": 400 * time.Millisecond}},
+		{Duration: time.Minute, PeakRSS: 900, Error: "exit status 1"},
+	}}
+	result.Summarize()
+	assert.Equal(1, result.Failures)
+	assert.Equal(2*time.Second, result.WallTime.Mean, "the failed runs should be ignored")
+	assert.Equal(int64(300), result.PeakRSS)
+	assert.Equal(map[string]time.Duration{"_default/single.html": 300 * time.Millisecond}, result.Templates)
+}
+
+func TestParseTemplateMetrics(t *testing.T) {
+	output := `Start building sites …
+Template Metrics:
+
+     cumulative       average       maximum      cache  percent  cached  total  
+       duration      duration      duration  potential   cached   count  count  template
+     ----------      --------      --------  ---------  -------  ------  -----  --------
+   1.2405124s    248.1024ms     512.103ms          0        0       0      5  _default/single.html
+    35.2009ms      35.2009ms     35.2009ms          0        0       0      1  index.html
+
+                   | EN
+-------------------+-----
+  Pages            | 10
+`
+	assert.Equal(t, map[string]time.Duration{
+		"_default/single.html": 1240512400 * time.Nanosecond,
+		"index.html":           35200900 * time.Nanosecond,
+	}, ParseTemplateMetrics(output))
+}
+
+func TestHotspots(t *testing.T) {
+	before := &Result{Templates: map[string]time.Duration{"a.html": time.Second, "b.html": time.Second, "gone.html": 100 * time.Millisecond}}
+	after := &Result{Templates: map[string]time.Duration{"a.html": 1500 * time.Millisecond, "b.html": 200 * time.Millisecond, "new.html": 50 * time.Millisecond}}
+
+	assert.Equal(t, []Hotspot{
+		{Template: "b.html", Before: time.Second, After: 200 * time.Millisecond},
+		{Template: "a.html", Before: time.Second, After: 1500 * time.Millisecond},
+		{Template: "gone.html", Before: 100 * time.Millisecond},
+	}, Hotspots(before, after, 3))
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/benchmark"
+	"github.com/TiboStev/hugo-wrapper/matrix"
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var benchVersions string
+var benchRuns int
+var benchOutput string
+var benchHotspots int
+
+var benchCmd = &cobra.Command{
+	Use:   "bench --versions <hugo-versions> [-- hugo_args]",
+	Short: "Benchmark the build of the site with several hugo versions",
+	Long: `Build the site several times with each of the comma separated versions, one build at a time,
+running hugo with --templateMetrics and the arguments given after --. The mean, median and
+standard deviation of the wall time, the peak memory and the templates whose duration changed
+the most from a version to the next are reported, and the results are saved as json.`,
+	Example: `hugo-wrapper bench --versions 0.115,0.120,latest --runs 5`,
+	Args:    cobra.ArbitraryArgs,
+	RunE:    runBench,
+}
+
+func init() {
+	benchCmd.Flags().StringVar(&benchVersions, "versions", "", "comma separated hugo versions to benchmark")
+	benchCmd.Flags().IntVar(&benchRuns, "runs", 5, "number of builds per version")
+	benchCmd.Flags().StringVar(&benchOutput, "output", "", "json file the results are saved to, hugo-bench-<date>.json by default")
+	benchCmd.Flags().IntVar(&benchHotspots, "hotspots", 10, "number of template hotspots reported between versions")
+	benchCmd.MarkFlagRequired("versions")
+	rootCmd.AddCommand(benchCmd)
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1")
+	}
+	hugoArgs := []string{}
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		hugoArgs = args[dash:]
+	}
+	manager, err := newManager(versionmanager.WithObserver(versionmanager.NewCLIObserver(os.Stderr)))
+	if err != nil {
+		return err
+	}
+	destination, err := ioutil.TempDir("", "hugo-wrapper-bench")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(destination)
+
+	report := &benchmark.Report{Date: time.Now(), Args: hugoArgs}
+	for _, build := range installBuilds(manager, strings.Split(benchVersions, ","), destination) {
+		if build.Error != "" {
+			return fmt.Errorf("%s: %s", build.Spec, build.Error)
+		}
+		result := &benchmark.Result{Spec: build.Spec, Version: build.Version}
+		for i := 1; i <= benchRuns; i++ {
+			fmt.Fprintf(os.Stderr, "%s: run %d/%d\n", build.Version, i, benchRuns)
+			result.Runs = append(result.Runs, benchRun(build, hugoArgs))
+		}
+		result.Summarize()
+		report.Results = append(report.Results, result)
+	}
+
+	printBench(report)
+	if benchOutput == "" {
+		benchOutput = "hugo-bench-" + report.Date.Format("20060102-150405") + ".json"
+	}
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(benchOutput, content, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("\nresults saved to %s\n", benchOutput)
+	return nil
+}
+
+func benchRun(build *matrix.Build, hugoArgs []string) benchmark.Run {
+	args := append(append([]string{}, hugoArgs...), "--templateMetrics", "--destination", build.Destination)
+	command := exec.Command(build.ExecPath, args...)
+	var output bytes.Buffer
+	command.Stdout = &output
+	command.Stderr = &output
+	start := time.Now()
+	err := command.Run()
+	run := benchmark.Run{Duration: time.Since(start)}
+	if command.ProcessState != nil {
+		run.PeakRSS = peakRSS(command.ProcessState)
+	}
+	if err != nil {
+		run.Error = err.Error()
+		fmt.Fprint(os.Stderr, output.String())
+		return run
+	}
+	run.Templates = benchmark.ParseTemplateMetrics(output.String())
+	os.RemoveAll(build.Destination)
+	return run
+}
+
+func printBench(report *benchmark.Report) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tMEAN\tMEDIAN\tSTDDEV\tPEAK RSS\tFAILURES")
+	for _, result := range report.Results {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%.1f MB\t%d/%d\n", result.Version,
+			result.WallTime.Mean.Round(time.Millisecond), result.WallTime.Median.Round(time.Millisecond),
+			result.WallTime.Stddev.Round(time.Millisecond), float64(result.PeakRSS)/1024/1024, result.Failures, len(result.Runs))
+	}
+	writer.Flush()
+	for i := 1; i < len(report.Results); i++ {
+		before, after := report.Results[i-1], report.Results[i]
+		hotspots := benchmark.Hotspots(before, after, benchHotspots)
+		if len(hotspots) == 0 {
+			continue
+		}
+		fmt.Printf("\ntemplate hotspots from %s to %s:\n", before.Version, after.Version)
+		writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, hotspot := range hotspots {
+			fmt.Fprintf(writer, "%s\t%s\t-> %s\t(%+.1fms)\n", hotspot.Template, hotspot.Before.Round(time.Microsecond),
+				hotspot.After.Round(time.Microsecond), float64(hotspot.Delta())/float64(time.Millisecond))
+		}
+		writer.Flush()
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/matrix"
+	"github.com/TiboStev/hugo-wrapper/sitediff"
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var bisectGood string
+var bisectBad string
+var bisectCheck string
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect --good <hugo-version> --bad <hugo-version> [-- hugo_args]",
+	Short: "Find the first hugo release breaking the build or changing the output",
+	Long: `Binary search the releases between a good and a bad version, installing each candidate.
+A candidate is good when the --check command exits with 0, the command running with the
+candidate first in the PATH and in HUGO_WRAPPER_VERSION. Without --check, a candidate is good
+when hugo, run with the arguments given after --, builds the site into the same output as the good version.`,
+	Example: `hugo-wrapper bisect --good 0.110 --bad 0.125 -- --minify
+hugo-wrapper bisect --good 0.110 --bad 0.125 --check "hugo --panicOnWarning"`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runBisect,
+}
+
+func init() {
+	bisectCmd.Flags().StringVar(&bisectGood, "good", "", "a version building the site as expected")
+	bisectCmd.Flags().StringVar(&bisectBad, "bad", "", "a later version breaking the build or changing its output")
+	bisectCmd.Flags().StringVar(&bisectCheck, "check", "", "shell command exiting with 0 for a good version")
+	bisectCmd.MarkFlagRequired("good")
+	bisectCmd.MarkFlagRequired("bad")
+	rootCmd.AddCommand(bisectCmd)
+}
+
+func runBisect(cmd *cobra.Command, args []string) error {
+	hugoArgs := []string{}
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		hugoArgs = args[dash:]
+	}
+	manager, err := newManager(versionmanager.WithObserver(versionmanager.NewCLIObserver(os.Stderr)))
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	good, err := manager.Resolve(ctx, bisectGood)
+	if err != nil {
+		return err
+	}
+	bad, err := manager.Resolve(ctx, bisectBad)
+	if err != nil {
+		return err
+	}
+	versions, err := manager.ReleasesBetween(ctx, good, bad)
+	if err != nil {
+		return err
+	}
+	workDirectory, err := ioutil.TempDir("", "hugo-wrapper-bisect")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDirectory)
+
+	isGood := func(version *versionmanager.Version) (bool, error) {
+		execPath, err := manager.Install(ctx, version)
+		if err != nil {
+			return false, err
+		}
+		return runCheck(version, execPath)
+	}
+	if bisectCheck == "" {
+		goodHash, err := buildHash(manager, good, hugoArgs, workDirectory)
+		if err != nil {
+			return err
+		}
+		if goodHash == "" {
+			return fmt.Errorf("the build with the good version %s failed", good)
+		}
+		isGood = func(version *versionmanager.Version) (bool, error) {
+			hash, err := buildHash(manager, version, hugoArgs, workDirectory)
+			return hash == goodHash, err
+		}
+	}
+
+	firstBad, err := versionmanager.Bisect(versions, func(version *versionmanager.Version, remaining int) (bool, error) {
+		fmt.Fprintf(os.Stderr, "checking %s, %d releases left to check\n", version, remaining)
+		isVersionGood, err := isGood(version)
+		if err == nil && isVersionGood {
+			fmt.Fprintf(os.Stderr, "%s is good\n", version)
+		} else if err == nil {
+			fmt.Fprintf(os.Stderr, "%s is bad\n", version)
+		}
+		return isVersionGood, err
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("first bad release: %s\nrelease notes: %s\n", firstBad, firstBad.ReleaseNotesURL())
+	return nil
+}
+
+// runCheck runs the check command of the user with version.
+func runCheck(version *versionmanager.Version, execPath string) (bool, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	command := exec.Command(shell, flag, bisectCheck)
+	command.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(execPath)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		hugoVersionEnv+"="+version.String())
+	command.Stdout = os.Stderr
+	command.Stderr = os.Stderr
+	err := command.Run()
+	if _, isExit := err.(*exec.ExitError); isExit {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// buildHash builds the site with version and returns the hash of its output,
+// or an empty hash when the build fails.
+func buildHash(manager *versionmanager.Manager, version *versionmanager.Version, hugoArgs []string, workDirectory string) (string, error) {
+	execPath, err := manager.Install(context.Background(), version)
+	if err != nil {
+		return "", err
+	}
+	build := &matrix.Build{
+		Version:       version.String(),
+		Destination:   filepath.Join(workDirectory, version.String()),
+		WorkDirectory: filepath.Join(workDirectory, "work", version.String()),
+		ExecPath:      execPath,
+	}
+	start := time.Now()
+	build.Run(hugoArgs)
+	if !build.Success {
+		fmt.Fprint(os.Stderr, build.Output)
+		return "", nil
+	}
+	return sitediff.Hash(build.Destination, sitediff.Options{BuildStart: start, BuildEnd: time.Now()})
+}
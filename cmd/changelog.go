@@ -0,0 +1,137 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var changelogGrep string
+var changelogRefresh bool
+
+// breakingPattern matches the lines and sections of the release notes worth a look before upgrading.
+var breakingPattern = regexp.MustCompile(`(?i)breaking|deprecat|removed`)
+
+var markdownHeading = regexp.MustCompile(`^(#+)\s`)
+
+const (
+	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
+	colorRed    = "\033[1;31m"
+	colorYellow = "\033[33m"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <from>..<to>",
+	Short: "Print the release notes of the releases between two versions",
+	Long: `Print, oldest first, the release notes of the releases after <from> up to <to>, <to> being
+the latest release when omitted. The lines and sections mentioning breaking changes, deprecations
+or removals are highlighted. The release notes are cached for a day.`,
+	Example: `hugo-wrapper changelog 0.112..0.121
+hugo-wrapper changelog 0.112.. --grep "markdown|goldmark"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChangelog,
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogGrep, "grep", "", "only print the lines matching this regular expression")
+	changelogCmd.Flags().BoolVar(&changelogRefresh, "refresh", false, "fetch the release notes again")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	bounds := strings.SplitN(args[0], "..", 2)
+	if len(bounds) != 2 || bounds[0] == "" {
+		return fmt.Errorf("the range must be in form of <from>..<to>, such as 0.112..0.121")
+	}
+	var grep *regexp.Regexp
+	if changelogGrep != "" {
+		var err error
+		if grep, err = regexp.Compile("(?i)" + changelogGrep); err != nil {
+			return fmt.Errorf("invalid --grep: %w", err)
+		}
+	}
+	options := []versionmanager.Option{}
+	if changelogRefresh {
+		options = append(options, versionmanager.WithCachePolicy(versionmanager.CacheRefresh))
+	}
+	manager, err := newManager(options...)
+	if err != nil {
+		return err
+	}
+	notes, err := manager.ReleaseNotes(context.Background(), bounds[0], bounds[1])
+	if err != nil {
+		return err
+	}
+	colored := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	for _, note := range notes {
+		printReleaseNote(note, grep, colored)
+	}
+	return nil
+}
+
+func printReleaseNote(note versionmanager.ReleaseNote, grep *regexp.Regexp, colored bool) {
+	paint := func(color string, text string) string {
+		if !colored {
+			return text
+		}
+		return color + text + colorReset
+	}
+	lines := []string{}
+	highlightedLevel := 0
+	for _, line := range strings.Split(note.Body, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if heading := markdownHeading.FindStringSubmatch(line); heading != nil {
+			level := len(heading[1])
+			if highlightedLevel > 0 && level <= highlightedLevel {
+				highlightedLevel = 0
+			}
+			if breakingPattern.MatchString(line) {
+				highlightedLevel = level
+			}
+		}
+		if grep != nil && !grep.MatchString(line) {
+			continue
+		}
+		switch {
+		case breakingPattern.MatchString(line):
+			line = paint(colorRed, line)
+		case highlightedLevel > 0:
+			line = paint(colorYellow, line)
+		}
+		lines = append(lines, line)
+	}
+	if grep != nil && len(lines) == 0 {
+		return
+	}
+	header := note.Version
+	if !note.PublishedAt.IsZero() {
+		header += " (" + note.PublishedAt.Format("2006-01-02") + ")"
+	}
+	fmt.Printf("%s %s\n\n%s\n\n", paint(colorBold, header), note.URL(), strings.Join(lines, "\n"))
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/matrix"
+	"github.com/TiboStev/hugo-wrapper/sitediff"
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var compareFormat string
+var compareKeep bool
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <hugo-version-a> <hugo-version-b> [-- hugo_args]",
+	Short: "Compare the output of the site built with two hugo versions",
+	Long: `Build the site with both versions into temporary directories, running hugo with the
+arguments given after --, and report the files added, removed and changed by the second version,
+with their size deltas and the unified diffs of the text files. The generator tag of hugo and
+the timestamps written at build time are ignored.`,
+	Example: `hugo-wrapper compare 0.115 0.120 -- --minify`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		versions := args
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			versions = args[:dash]
+		}
+		if len(versions) != 2 {
+			return fmt.Errorf("compare requires two versions, got %d", len(versions))
+		}
+		return nil
+	},
+	RunE: runCompare,
+}
+
+func init() {
+	compareCmd.Flags().StringVar(&compareFormat, "format", "text", "format of the report: text or json")
+	compareCmd.Flags().BoolVar(&compareKeep, "keep", false, "keep the output of the builds")
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	if compareFormat != "text" && compareFormat != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", compareFormat)
+	}
+	hugoArgs := []string{}
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		hugoArgs = args[dash:]
+	}
+	manager, err := newManager(versionmanager.WithObserver(versionmanager.NewCLIObserver(os.Stderr)))
+	if err != nil {
+		return err
+	}
+	destination, err := ioutil.TempDir("", "hugo-wrapper-compare")
+	if err != nil {
+		return err
+	}
+	if compareKeep {
+		fmt.Fprintf(os.Stderr, "the builds are kept in %s\n", destination)
+	} else {
+		defer os.RemoveAll(destination)
+	}
+	builds := installBuilds(manager, args[:2], destination)
+	if len(builds) < 2 {
+		return fmt.Errorf("%s and %s resolve to the same version", args[0], args[1])
+	}
+	start := time.Now()
+	for _, build := range builds {
+		if build.Error == "" {
+			build.Run(hugoArgs)
+		}
+		if !build.Success {
+			return fmt.Errorf("the build with %s failed: %s\n%s", build.Spec, build.Error, build.Output)
+		}
+	}
+	report, err := sitediff.Compare(builds[0].Destination, builds[1].Destination, sitediff.Options{BuildStart: start, BuildEnd: time.Now()})
+	if err != nil {
+		return err
+	}
+	if compareFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	printComparison(builds[0], builds[1], report)
+	return nil
+}
+
+func printComparison(buildA *matrix.Build, buildB *matrix.Build, report *sitediff.Report) {
+	fmt.Printf("%s -> %s: %d files changed, %d unchanged\n", buildA.Version, buildB.Version, len(report.Changes), report.Unchanged)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, change := range report.Changes {
+		fmt.Fprintf(writer, "%s\t%s\t%+d bytes\n", change.Kind, change.Path, change.SizeDelta())
+	}
+	writer.Flush()
+	for _, change := range report.Changes {
+		if change.Diff != "" {
+			fmt.Printf("\n%s", change.Diff)
+		}
+	}
+}
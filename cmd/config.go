@@ -0,0 +1,217 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+// configFiles are the user configuration and the closest project configuration,
+// the project one being empty when there is none. The paths are the files they have been read from.
+type configFiles struct {
+	userPath    string
+	user        *versionmanager.Config
+	projectPath string
+	project     *versionmanager.Config
+}
+
+func (files *configFiles) merged() *versionmanager.Config {
+	return files.user.Merge(files.project)
+}
+
+// userConfigPath returns the path of the user configuration, in $XDG_CONFIG_HOME or ~/.config.
+func userConfigPath() (string, error) {
+	directory := os.Getenv("XDG_CONFIG_HOME")
+	if directory == "" {
+		homePath, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		directory = filepath.Join(homePath, ".config")
+	}
+	return filepath.Join(directory, "hugo-wrapper", versionmanager.ConfigFileName), nil
+}
+
+// readUserConfig reads the user configuration, or the default version saved
+// in ~/.hugo-wrapper/config.toml by the former versions until it is written.
+// path is where the configuration is written, readPath the file it has been read from.
+func readUserConfig() (config *versionmanager.Config, path string, readPath string, err error) {
+	if path, err = userConfigPath(); err != nil {
+		return nil, "", "", err
+	}
+	readPath = path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if homePath, err := homedir.Dir(); err == nil {
+			legacyPath := filepath.Join(homePath, ".hugo-wrapper", "config.toml")
+			if _, err := os.Stat(legacyPath); err == nil {
+				readPath = legacyPath
+			}
+		}
+	}
+	config, err = versionmanager.ReadConfig(readPath)
+	return config, path, readPath, err
+}
+
+func loadConfigFiles() (*configFiles, error) {
+	files := &configFiles{project: new(versionmanager.Config)}
+	var err error
+	if files.user, _, files.userPath, err = readUserConfig(); err != nil {
+		return nil, err
+	}
+	workingDirectory, _ := os.Getwd()
+	if files.projectPath, err = versionmanager.FindProjectConfig(workingDirectory); err != nil {
+		return nil, err
+	}
+	if files.projectPath != "" {
+		if files.project, err = versionmanager.ReadConfig(files.projectPath); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// loadConfig returns the user configuration overridden by the project one.
+func loadConfig() (*versionmanager.Config, error) {
+	files, err := loadConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+	return files.merged(), nil
+}
+
+var configProject bool
+
+var configCmd = &cobra.Command{
+	Use:   "wrapper-config",
+	Short: "Read and change the configuration of the wrapper",
+	Long: `Read and change the configuration of the wrapper, saved in ~/.config/hugo-wrapper/config.yaml,
+or in $XDG_CONFIG_HOME/hugo-wrapper/config.yaml. The settings of the closest .hugo-wrapper.yaml
+of the project override the user ones.`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the settings and where they are set",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting, an empty value unsetting it",
+	Long: `Change a setting in the user configuration, or with --project in the closest .hugo-wrapper.yaml,
+created in the current directory when there is none. An empty value unsets the setting.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+func init() {
+	configSetCmd.Flags().BoolVar(&configProject, "project", false, "change the configuration of the project")
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	files, err := loadConfigFiles()
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tVALUE\tSET IN\t")
+	for _, key := range versionmanager.ConfigKeys() {
+		value, origin := key.Get(files.user), files.userPath
+		if projectValue := key.Get(files.project); projectValue != "" {
+			value, origin = projectValue, files.projectPath
+		}
+		switch {
+		case value == "":
+			value, origin = "-", "default"
+		case key.Secret:
+			value = "********"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t\n", key.Name, value, origin)
+	}
+	return writer.Flush()
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	key, err := versionmanager.LookupConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Println(key.Get(config))
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, err := versionmanager.LookupConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+	if configProject && !key.Project {
+		return fmt.Errorf("%s can only be set in the user configuration", key.Name)
+	}
+	var config *versionmanager.Config
+	var path string
+	if configProject {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if path, err = versionmanager.FindProjectConfig(workingDirectory); err != nil {
+			return err
+		}
+		if path == "" {
+			path = filepath.Join(workingDirectory, versionmanager.ProjectConfigFileName)
+		}
+		config, err = versionmanager.ReadConfig(path)
+	} else {
+		config, path, _, err = readUserConfig()
+	}
+	if err != nil {
+		return err
+	}
+	if err = key.Set(config, args[1]); err != nil {
+		return err
+	}
+	if err = config.Write(path); err != nil {
+		return err
+	}
+	if args[1] == "" {
+		fmt.Printf("%s unset in %s\n", key.Name, path)
+		return nil
+	}
+	fmt.Printf("%s set in %s\n", key.Name, path)
+	return nil
+}
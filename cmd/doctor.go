@@ -0,0 +1,280 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+// minimumFreeSpace leaves room for the extraction of an extended release.
+const minimumFreeSpace = 200 * 1024 * 1024
+
+// minimumGlibc is the oldest glibc the extended builds, dynamically linked against it, run on.
+var minimumGlibc = [2]int{2, 27}
+
+type checkStatus string
+
+const (
+	checkPass = checkStatus("pass")
+	checkWarn = checkStatus("warn")
+	checkFail = checkStatus("fail")
+)
+
+type checkResult struct {
+	status  checkStatus
+	name    string
+	message string
+	fix     string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment of the wrapper",
+	Long: `Diagnose the environment of the wrapper: install directory, network access to GitHub,
+proxy and certificates, conflicting hugo executables, platform detection, availability of the
+selected version and health of the installed versions.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	results := checkInstallDirectory(manager.InstallDirectory())
+	results = append(results, checkGithubAPI(manager)...)
+	results = append(results, checkProxy()...)
+	results = append(results, checkSystemHugo(manager.InstallDirectory()))
+	results = append(results, checkPlatform())
+	results = append(results, checkSelectedAsset(cmd, manager))
+	results = append(results, checkGlibc())
+	results = append(results, checkInstallations(manager)...)
+
+	failures := 0
+	for _, result := range results {
+		fmt.Printf("[%s] %s: %s\n", result.status, result.name, result.message)
+		if result.fix != "" && result.status != checkPass {
+			fmt.Printf("       fix: %s\n", result.fix)
+		}
+		if result.status == checkFail {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d checks failed", failures)
+	}
+	return nil
+}
+
+func checkInstallDirectory(directory string) []checkResult {
+	name := "install directory"
+	probe, err := ioutil.TempFile(directory, ".doctor")
+	if err != nil {
+		return []checkResult{{checkFail, name, fmt.Sprintf("%s is not writable: %s", directory, err), "fix the permissions of " + directory + ", or set " + installHomeEnv + " to a writable directory"}}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	results := []checkResult{{checkPass, name, directory + " is writable", ""}}
+
+	name = "free space"
+	available, err := freeSpace(directory)
+	switch {
+	case err != nil:
+		results = append(results, checkResult{checkWarn, name, "unable to compute the free space: " + err.Error(), ""})
+	case available < minimumFreeSpace:
+		results = append(results, checkResult{checkWarn, name, fmt.Sprintf("only %d MB available in %s", available/1024/1024, directory), "free some space or remove unused versions from " + directory})
+	default:
+		results = append(results, checkResult{checkPass, name, fmt.Sprintf("%d MB available", available/1024/1024), ""})
+	}
+	return results
+}
+
+func checkGithubAPI(manager *versionmanager.Manager) []checkResult {
+	results := []checkResult{}
+	for _, backend := range manager.Backends() {
+		if limiter, ok := backend.(versionmanager.RateLimitedClient); ok {
+			results = append(results, checkRateLimit(limiter))
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, checkResult{checkWarn, "github api", "the repository service doesn't report its rate limit", ""})
+	}
+	return results
+}
+
+func checkRateLimit(limiter versionmanager.RateLimitedClient) checkResult {
+	name := "github api"
+	rateLimit, err := limiter.GetRateLimit(context.Background())
+	if err != nil {
+		return checkResult{checkFail, name, "unreachable: " + err.Error(), "check the network connection and the proxy configuration"}
+	}
+	message := fmt.Sprintf("reachable, %d/%d requests left until %s", rateLimit.Remaining, rateLimit.Limit, rateLimit.Reset.Format(time.Kitchen))
+	switch {
+	case rateLimit.Remaining == 0:
+		return checkResult{checkFail, name, message, "wait until " + rateLimit.Reset.Format(time.Kitchen) + " for the rate limit to be reset"}
+	case rateLimit.Remaining < 10:
+		return checkResult{checkWarn, name, message, "avoid resolving versions until " + rateLimit.Reset.Format(time.Kitchen)}
+	}
+	return checkResult{checkPass, name, message, ""}
+}
+
+func checkProxy() []checkResult {
+	results := []checkResult{}
+	name := "proxy"
+	request, _ := http.NewRequest(http.MethodGet, "https://api.github.com", nil)
+	proxy, err := http.ProxyFromEnvironment(request)
+	switch {
+	case err != nil:
+		results = append(results, checkResult{checkFail, name, "invalid configuration: " + err.Error(), "correct the HTTPS_PROXY environment variable"})
+	case proxy != nil:
+		results = append(results, checkResult{checkPass, name, "requests to github go through " + proxy.Host, ""})
+	default:
+		results = append(results, checkResult{checkPass, name, "no proxy configured", ""})
+	}
+
+	name = "certificates"
+	for _, variable := range []string{"SSL_CERT_FILE", "SSL_CERT_DIR"} {
+		if location := os.Getenv(variable); location != "" {
+			if _, err := os.Stat(location); err != nil {
+				results = append(results, checkResult{checkFail, name, fmt.Sprintf("%s points to %s which is not readable", variable, location), "unset " + variable + " or make it point to existing certificates"})
+				return results
+			}
+		}
+	}
+	if _, err := x509.SystemCertPool(); err != nil {
+		return append(results, checkResult{checkWarn, name, "unable to load the system certificates: " + err.Error(), "set SSL_CERT_FILE to a CA bundle"})
+	}
+	return append(results, checkResult{checkPass, name, "system certificates loaded", ""})
+}
+
+func checkSystemHugo(installDirectory string) checkResult {
+	name := "system hugo"
+	systemHugo, err := exec.LookPath("hugo")
+	if err != nil {
+		return checkResult{checkPass, name, "no hugo executable on PATH", ""}
+	}
+	systemHugo, _ = filepath.Abs(systemHugo)
+	if strings.HasPrefix(systemHugo, installDirectory) {
+		return checkResult{checkPass, name, systemHugo + " is managed by the wrapper", ""}
+	}
+	return checkResult{checkWarn, name, systemHugo + " is on PATH, running hugo directly bypasses the wrapper", "run hugo through hugo-wrapper or remove " + filepath.Dir(systemHugo) + " from PATH"}
+}
+
+func checkPlatform() checkResult {
+	name := "platform"
+	assetOs, assetArch, err := versionmanager.Platform()
+	if err != nil {
+		return checkResult{checkFail, name, err.Error(), "build hugo from source for this platform"}
+	}
+	return checkResult{checkPass, name, fmt.Sprintf("%s/%s uses the %s-%s assets", runtime.GOOS, runtime.GOARCH, assetOs, assetArch), ""}
+}
+
+func checkSelectedAsset(cmd *cobra.Command, manager *versionmanager.Manager) checkResult {
+	name := "selected version"
+	resolution, err := resolveSpec(cmd)
+	if err != nil {
+		return checkResult{checkFail, name, err.Error(), ""}
+	}
+	version, err := manager.Resolve(context.Background(), resolution.Spec)
+	if err != nil {
+		return checkResult{checkFail, name, fmt.Sprintf("%s (from %s) can't be resolved: %s", resolution.Spec, resolution.Source, resolution.ExplainError(err)), "check the network connection, or select an existing version"}
+	}
+	assetName, _ := version.AssetName()
+	if _, err := version.AssetURL(); err != nil {
+		return checkResult{checkFail, name, err.Error(), "select a version providing " + assetName + ", or a non extended one"}
+	}
+	return checkResult{checkPass, name, fmt.Sprintf("%s provides %s", version, assetName), ""}
+}
+
+func checkGlibc() checkResult {
+	name := "extended builds"
+	if runtime.GOOS != "linux" {
+		return checkResult{checkPass, name, "no libc requirement on " + runtime.GOOS, ""}
+	}
+	if output, err := exec.Command("getconf", "GNU_LIBC_VERSION").Output(); err == nil {
+		found := strings.TrimSpace(string(output))
+		major, minor, ok := parseGlibcVersion(found)
+		switch {
+		case !ok:
+			return checkResult{checkWarn, name, fmt.Sprintf("unable to read the glibc version from %q, extended builds need glibc %d.%d", found, minimumGlibc[0], minimumGlibc[1]), ""}
+		case major < minimumGlibc[0] || major == minimumGlibc[0] && minor < minimumGlibc[1]:
+			return checkResult{checkFail, name, fmt.Sprintf("%s found, extended builds are linked against glibc %d.%d and won't run", found, minimumGlibc[0], minimumGlibc[1]), "upgrade the system, or use a non extended version"}
+		}
+		return checkResult{checkPass, name, found + " found", ""}
+	}
+	if loaders, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(loaders) > 0 {
+		return checkResult{checkWarn, name, "musl libc detected, extended builds are linked against glibc and won't run", "install a glibc compatibility layer such as gcompat, or use a non extended version"}
+	}
+	return checkResult{checkWarn, name, "glibc not detected, extended builds may not run", "install glibc or use a non extended version"}
+}
+
+// parseGlibcVersion reads the output of getconf GNU_LIBC_VERSION, such as "glibc 2.31".
+func parseGlibcVersion(output string) (major int, minor int, ok bool) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+	parts := strings.SplitN(fields[1], ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, majorErr := strconv.Atoi(parts[0])
+	minor, minorErr := strconv.Atoi(parts[1])
+	return major, minor, majorErr == nil && minorErr == nil
+}
+
+func checkInstallations(manager *versionmanager.Manager) []checkResult {
+	name := "installed versions"
+	installations, err := manager.Installations()
+	if err != nil {
+		return []checkResult{{checkFail, name, err.Error(), ""}}
+	}
+	if len(installations) == 0 {
+		return []checkResult{{checkPass, name, "none", ""}}
+	}
+	results := []checkResult{}
+	for _, installation := range installations {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		output, err := exec.CommandContext(ctx, installation.ExecPath, "version").CombinedOutput()
+		cancel()
+		if err != nil {
+			fix := "remove " + filepath.Dir(installation.ExecPath) + " to reinstall it on the next run"
+			results = append(results, checkResult{checkFail, installation.Version, "hugo version failed: " + err.Error(), fix})
+			continue
+		}
+		results = append(results, checkResult{checkPass, installation.Version, strings.TrimSpace(string(output)), ""})
+	}
+	return results
+}
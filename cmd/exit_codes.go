@@ -0,0 +1,57 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+)
+
+// The exit codes of the wrapper start at 100, so that they can't be mistaken
+// for the exit code of hugo, which is passed through as it is.
+const (
+	exitWrapperError       = 100
+	exitInvalidVersionSpec = 101
+	exitVersionNotFound    = 102
+	exitAssetNotFound      = 103
+	exitRateLimited        = 104
+	exitChecksumMismatch   = 105
+	exitOffline            = 106
+	exitOutdated           = 107
+)
+
+func exitCode(err error) int {
+	var outdated *outdatedError
+	switch {
+	case errors.As(err, &outdated):
+		return exitOutdated
+	case errors.Is(err, versionmanager.ErrInvalidVersionSpec):
+		return exitInvalidVersionSpec
+	case errors.Is(err, versionmanager.ErrVersionNotFound):
+		return exitVersionNotFound
+	case errors.Is(err, versionmanager.ErrAssetNotFound):
+		return exitAssetNotFound
+	case errors.Is(err, versionmanager.ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, versionmanager.ErrChecksumMismatch):
+		return exitChecksumMismatch
+	case errors.Is(err, versionmanager.ErrOffline):
+		return exitOffline
+	default:
+		return exitWrapperError
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var localCmd = &cobra.Command{
+	Use:   "local <hugo-version>",
+	Short: "Pin the hugo version of the project",
+	Long: `Pin the hugo version of the project in a .hugo-version file of the current directory.
+The version is resolved before being saved, and installed right away when accepted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLocal,
+}
+
+func init() {
+	localCmd.Flags().BoolVar(&installNow, "install", false, "install the version without asking")
+	rootCmd.AddCommand(localCmd)
+}
+
+func runLocal(cmd *cobra.Command, args []string) error {
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	spec := args[0]
+	version, err := validateSpec(manager, spec)
+	if err != nil {
+		return err
+	}
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	path, err := versionmanager.WritePinFile(workingDirectory, spec)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s pinned to %s (currently %s)\n", path, spec, version)
+	return offerInstall(manager, version)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// installHomeEnv is the directory holding the versions and the caches, overriding every other location.
+const installHomeEnv = "HUGO_WRAPPER_HOME"
+
+// portableEnv keeps the versions next to the executable of the wrapper when set to true.
+const portableEnv = "HUGO_WRAPPER_PORTABLE"
+
+// portableMarker enables the portable mode when it is found next to the executable of the wrapper.
+const portableMarker = "hugo-wrapper.portable"
+
+// portableDirectory holds the versions and the caches of the portable mode, next to the executable.
+const portableDirectory = "hugo-wrapper-data"
+
+// installLocation is where the versions are installed and the release metadata cached.
+type installLocation struct {
+	install string
+	cache   string
+	// migrated locations receive the versions of the location used previously.
+	migrated bool
+}
+
+// resolveInstallLocation returns, in order of precedence, HUGO_WRAPPER_HOME, the
+// install_dir of the configuration, the directory of the portable mode,
+// $XDG_DATA_HOME/hugo-wrapper and $XDG_CACHE_HOME/hugo-wrapper, or ~/.hugo-wrapper.
+func resolveInstallLocation(config *versionmanager.Config) (*installLocation, error) {
+	if home := os.Getenv(installHomeEnv); home != "" {
+		return &installLocation{install: home, cache: home}, nil
+	}
+	if config.InstallDir != "" {
+		directory, err := homedir.Expand(config.InstallDir)
+		if err != nil {
+			return nil, err
+		}
+		return &installLocation{install: directory, cache: directory, migrated: true}, nil
+	}
+	if directory, portable, err := portableLocation(); err != nil || portable {
+		return &installLocation{install: directory, cache: directory}, err
+	}
+	location := &installLocation{migrated: true}
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		location.install = filepath.Join(dataHome, "hugo-wrapper")
+	} else {
+		homePath, err := homedir.Dir()
+		if err != nil {
+			return nil, fmt.Errorf("the home directory can't be found, set %s to the directory of the installed versions: %w", installHomeEnv, err)
+		}
+		location.install = filepath.Join(homePath, ".hugo-wrapper")
+	}
+	location.cache = location.install
+	if cacheHome := os.Getenv("XDG_CACHE_HOME"); cacheHome != "" {
+		location.cache = filepath.Join(cacheHome, "hugo-wrapper")
+	}
+	return location, nil
+}
+
+// portableLocation tells if the portable mode is enabled, by HUGO_WRAPPER_PORTABLE
+// or by a hugo-wrapper.portable file next to the executable, and returns its directory.
+func portableLocation() (string, bool, error) {
+	portable := false
+	if value, found := os.LookupEnv(portableEnv); found {
+		var err error
+		if portable, err = strconv.ParseBool(value); err != nil {
+			return "", false, fmt.Errorf("%s: %w", portableEnv, err)
+		}
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "", false, nil
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return "", false, nil
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(executable), portableMarker)); err == nil {
+		portable = true
+	}
+	return filepath.Join(filepath.Dir(executable), portableDirectory), portable, nil
+}
+
+// prepareInstallLocation creates the install directory, and moves into it the
+// versions of the location used by the previous run when it has changed.
+func prepareInstallLocation(location *installLocation) error {
+	if err := os.MkdirAll(location.install, 0770); err != nil {
+		return fmt.Errorf("the install directory %s can't be created, set %s to a writable directory: %w", location.install, installHomeEnv, err)
+	}
+	if location.migrated {
+		migrateInstallDirectory(location)
+	}
+	return nil
+}
+
+// installRecordPath returns the file recording the install directory of the last run.
+func installRecordPath() (string, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "install-directory"), nil
+}
+
+// migrateInstallDirectory moves the versions of the install directory recorded
+// by the last run, ~/.hugo-wrapper when none has been, into the location. The
+// migration is skipped when the record can't be saved, such as in a read-only
+// home, as it would be tried again by every run. A failed migration is reported
+// and tried again by the next run.
+func migrateInstallDirectory(location *installLocation) {
+	recordPath, err := installRecordPath()
+	if err != nil {
+		return
+	}
+	recorded := ""
+	if content, err := ioutil.ReadFile(recordPath); err == nil {
+		recorded = strings.TrimSpace(string(content))
+	}
+	if recorded == location.install {
+		return
+	}
+	previous := recorded
+	if previous == "" {
+		if homePath, err := homedir.Dir(); err == nil {
+			previous = filepath.Join(homePath, ".hugo-wrapper")
+		}
+	}
+	// the previous location stays recorded until the migration succeeds
+	if err := writeInstallRecord(recordPath, previous); err != nil {
+		return
+	}
+	if previous != "" && filepath.Clean(previous) != filepath.Clean(location.install) {
+		if _, err := os.Stat(previous); err == nil {
+			// the former configuration file is read where it is until the configuration is written
+			moved, err := versionmanager.MigrateInstallDirectory(previous, location.install, location.cache, "config.toml")
+			if len(moved) > 0 {
+				fmt.Fprintf(os.Stderr, "moved %s from %s to %s\n", strings.Join(moved, ", "), previous, location.install)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: the installed versions can't be moved from %s: %s\n", previous, err)
+				return
+			}
+		}
+	}
+	writeInstallRecord(recordPath, location.install)
+}
+
+func writeInstallRecord(recordPath string, directory string) error {
+	if err := os.MkdirAll(filepath.Dir(recordPath), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(recordPath, []byte(directory+"\n"), 0644)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/TiboStev/hugo-wrapper/matrix"
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var matrixVersions string
+var matrixDestination string
+var matrixFormat string
+var matrixJobs int
+
+var matrixCmd = &cobra.Command{
+	Use:   "matrix --versions <hugo-versions> [-- hugo_args]",
+	Short: "Build the site with several hugo versions",
+	Long: `Build the site with each of the comma separated versions, running hugo with the arguments
+given after --. Each version builds into its own directory of --destination, with its own resource
+and cache directories. The builds run one at a time unless --jobs allows more, the builds running
+at the same time competing for the processors and for the build lock of the site, which the recent
+versions of hugo take: their durations are then not comparable.
+The success, the duration, the number of warnings and the size of the output of each build
+are reported as a table, as json or as JUnit XML with --format.`,
+	Example: `hugo-wrapper matrix --versions "0.115,0.120,latest-extended" -- --minify`,
+	Args:    cobra.ArbitraryArgs,
+	RunE:    runMatrix,
+}
+
+func init() {
+	matrixCmd.Flags().StringVar(&matrixVersions, "versions", "", "comma separated hugo versions to build with")
+	matrixCmd.Flags().StringVar(&matrixDestination, "destination", "matrix", "directory holding the output of each version")
+	matrixCmd.Flags().StringVar(&matrixFormat, "format", "table", "format of the report: table, json or junit")
+	matrixCmd.Flags().IntVar(&matrixJobs, "jobs", 1, "number of builds running at the same time")
+	matrixCmd.MarkFlagRequired("versions")
+	rootCmd.AddCommand(matrixCmd)
+}
+
+func runMatrix(cmd *cobra.Command, args []string) error {
+	if matrixFormat != "table" && matrixFormat != "json" && matrixFormat != "junit" {
+		return fmt.Errorf("unknown format %q, expected table, json or junit", matrixFormat)
+	}
+	if matrixJobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+	manager, err := newManager(versionmanager.WithObserver(versionmanager.NewCLIObserver(os.Stderr)))
+	if err != nil {
+		return err
+	}
+	builds := installBuilds(manager, strings.Split(matrixVersions, ","), matrixDestination)
+	hugoArgs := args
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		hugoArgs = args[dash:]
+	}
+
+	var waitGroup sync.WaitGroup
+	slots := make(chan struct{}, matrixJobs)
+	for _, build := range builds {
+		if build.Error != "" {
+			continue
+		}
+		waitGroup.Add(1)
+		go func(build *matrix.Build) {
+			defer waitGroup.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			build.Run(hugoArgs)
+		}(build)
+	}
+	waitGroup.Wait()
+
+	switch matrixFormat {
+	case "json":
+		err = matrix.WriteJSON(os.Stdout, builds)
+	case "junit":
+		err = matrix.WriteJUnit(os.Stdout, builds)
+	default:
+		err = matrix.WriteTable(os.Stdout, builds)
+	}
+	if err != nil {
+		return err
+	}
+	failures := 0
+	for _, build := range builds {
+		if !build.Success {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d builds failed", failures, len(builds))
+	}
+	return nil
+}
+
+// installBuilds installs the versions one at a time, the specs resolving to the same version being built once.
+func installBuilds(manager *versionmanager.Manager, specs []string, destination string) []*matrix.Build {
+	return matrix.Plan(specs, destination, manager.GetExecPath, os.Stderr)
+}
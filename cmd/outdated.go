@@ -0,0 +1,84 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var outdatedCI bool
+var maxMinorsBehind int
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Compare the selected hugo version with the newest releases",
+	Long: `Compare the selected hugo version with the newest release of its minor line, of its major line
+and overall. With --ci, the command fails when the version is more than --max-minors-behind
+minor lines behind the newest release.`,
+	Args: cobra.NoArgs,
+	RunE: runOutdated,
+}
+
+func init() {
+	outdatedCmd.Flags().BoolVar(&outdatedCI, "ci", false, "fail when the version is too many minor lines behind")
+	outdatedCmd.Flags().IntVar(&maxMinorsBehind, "max-minors-behind", 2, "number of minor lines the version may be behind with --ci")
+	rootCmd.AddCommand(outdatedCmd)
+}
+
+func runOutdated(cmd *cobra.Command, args []string) error {
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	resolution, err := resolveSpec(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	version, err := manager.Resolve(ctx, resolution.Spec)
+	if err != nil {
+		return resolution.ExplainError(err)
+	}
+	outdated, err := manager.CheckOutdated(ctx, version)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current: %s (%s from %s)\n", outdated.Current, resolution.Spec, resolution.Source)
+	fmt.Printf("latest patch of its minor line: %s\n", outdated.LatestPatch)
+	fmt.Printf("latest minor of its major line: %s\n", outdated.LatestMinor)
+	fmt.Printf("latest: %s\n", outdated.Latest)
+	if outdated.MinorsBehind > 0 {
+		fmt.Printf("%d minor lines behind\n", outdated.MinorsBehind)
+	} else if !outdated.IsOutdated() {
+		fmt.Println("up to date")
+	}
+	if outdatedCI && outdated.MinorsBehind > maxMinorsBehind {
+		return &outdatedError{minorsBehind: outdated.MinorsBehind, max: maxMinorsBehind}
+	}
+	return nil
+}
+
+type outdatedError struct {
+	minorsBehind int
+	max          int
+}
+
+func (err *outdatedError) Error() string {
+	return fmt.Sprintf("the hugo version is %d minor lines behind, at most %d are allowed", err.minorsBehind, err.max)
+}
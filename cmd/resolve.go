@@ -0,0 +1,100 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var explain bool
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "Print the hugo version that would be used",
+	Long: `Print the hugo version that would be used.
+With --explain, every step of the resolution is detailed: the sources consulted,
+the releases considered, the asset chosen and whether the network was used.`,
+	Args: cobra.NoArgs,
+	RunE: runResolve,
+}
+
+func init() {
+	resolveCmd.Flags().BoolVar(&explain, "explain", false, "detail how the version has been resolved")
+	rootCmd.AddCommand(resolveCmd)
+}
+
+func runResolve(cmd *cobra.Command, args []string) error {
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	resolution, err := resolveSpec(cmd)
+	if err != nil {
+		return err
+	}
+	explanation, err := manager.Explain(resolution.Spec)
+	if err != nil {
+		return resolution.ExplainError(err)
+	}
+	if !explain {
+		fmt.Println(explanation.Version)
+		return nil
+	}
+	printExplanation(resolution, explanation)
+	return nil
+}
+
+func printExplanation(resolution *versionmanager.SpecResolution, explanation *versionmanager.Explanation) {
+	fmt.Println("sources consulted:")
+	for _, result := range resolution.Consulted {
+		if result.Found && result.Declaration != "" {
+			fmt.Printf("  %s: %s (declared as %q)\n", result.Source, result.Spec, result.Declaration)
+		} else if result.Found {
+			fmt.Printf("  %s: %s\n", result.Source, result.Spec)
+		} else {
+			fmt.Printf("  %s: not set\n", result.Source)
+		}
+	}
+	if len(resolution.Requirements) > 0 {
+		fmt.Println("requirements:")
+		for _, requirement := range resolution.Requirements {
+			fmt.Printf("  %s\n", requirement)
+		}
+	}
+	if resolution.ExtendedReason != "" {
+		fmt.Printf("extended edition: required, %s\n", resolution.ExtendedReason)
+	}
+	fmt.Printf("spec: %s (from %s)\n", resolution.Spec, resolution.Source)
+	fmt.Println("releases considered:")
+	for _, candidate := range explanation.Candidates {
+		fmt.Printf("  %s\n", candidate)
+	}
+	fmt.Printf("version: %s\n", explanation.Version)
+	fmt.Printf("asset: %s\n", explanation.AssetName)
+	if explanation.RemoteCalls == 0 {
+		fmt.Println("release metadata: from the cache, no request made")
+	} else {
+		fmt.Printf("release metadata: fetched from the network (%d requests)\n", explanation.RemoteCalls)
+	}
+	if explanation.Installed {
+		fmt.Printf("executable: %s (already installed, no download needed)\n", explanation.ExecPath)
+	} else {
+		fmt.Printf("executable: %s (not installed, the asset will be downloaded)\n", explanation.ExecPath)
+	}
+}
@@ -16,13 +16,17 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/TiboStev/hugo-wrapper/versionmanager"
-	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -31,36 +35,66 @@ import (
 
 var hugoVersion string
 
+// hugoVersionEnv is the environment variable declaring the hugo version when the flag isn't set.
+const hugoVersionEnv = "HUGO_WRAPPER_VERSION"
+
+// minReleaseAgeEnv is the number of days a release must have been published to be selected by latest-stable.
+const minReleaseAgeEnv = "HUGO_WRAPPER_MIN_RELEASE_AGE"
+
+// previousMinorEnv makes latest-stable select the latest patch of the previous minor line when set to true.
+const previousMinorEnv = "HUGO_WRAPPER_PREFER_PREVIOUS_MINOR"
+
+// backendsEnv lists the backends the releases are fetched from, see versionmanager.ParseBackends.
+const backendsEnv = "HUGO_WRAPPER_BACKENDS"
+
 //var onWrapper bool
 var rootCmd = &cobra.Command{
 	Use:                "hugo-wrapper",
 	Short:              "Wrap hugo command",
 	Long:               `This is a wrapper for the hugo command, it allows to use different version of hugo without struggle.`,
 	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+	SilenceErrors:      true,
+	SilenceUsage:       true,
 	Args:               cobra.ArbitraryArgs,
-	PersistentPreRun:   persistantPreRun,
-	PersistentPostRun:  persistantPostRun,
-	Run:                func(cmd *cobra.Command, args []string) {},
+	PreRun:             collectWrappedArgs,
+	RunE:               runHugo,
 }
 
 // Execute ads all child commands to the root command and sets flags appropriately.
 // This is called y main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	err := rootCmd.Execute()
+	applyRetention()
+	if err == nil {
+		return
+	}
+	var hugoExit *exec.ExitError
+	if errors.As(err, &hugoExit) {
+		os.Exit(hugoExit.ExitCode())
 	}
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(exitCode(err))
 }
 
+// notifyEnv enables, when set to true, a daily notice of the newer patches of the line of the version used.
+const notifyEnv = "HUGO_WRAPPER_NOTIFY"
+
+// detectExtended enables the selection of the extended edition for the sites needing it.
+var detectExtended bool
+
+// detectExtendedEnv disables the detection of the extended edition when set to false.
+const detectExtendedEnv = "HUGO_WRAPPER_DETECT_EXTENDED"
+
 func init() {
 	rootCmd.PersistentFlags().StringVar(&hugoVersion, "hugo-version", "latest", "use this specific hugo version")
+	rootCmd.PersistentFlags().BoolVar(&detectExtended, "hugo-detect-extended", true, "select the extended edition when the site needs it")
 }
 
 var wrappedArgs []string
 var wrappedFlags []*pflag.Flag
 var hugoCommand *exec.Cmd
 
-func persistantPreRun(cmd *cobra.Command, args []string) {
+func collectWrappedArgs(cmd *cobra.Command, args []string) {
 	wrappedArgs = []string{}
 	if cmd.HasParent() {
 		wrappedArgs = append(wrappedArgs, cmd.Name())
@@ -78,37 +112,221 @@ func persistantPreRun(cmd *cobra.Command, args []string) {
 	})
 }
 
-func getHugoCommand(args []string) (*exec.Cmd, error) {
+// managers are the managers created by the command, the versions beyond the
+// retention are removed once it has finished.
+var managers []*versionmanager.Manager
 
-	homePath, err := homedir.Dir()
+func newManager(options ...versionmanager.Option) (*versionmanager.Manager, error) {
+	config, err := loadConfig()
 	if err != nil {
 		return nil, err
 	}
-	hugoVersionManagerPath := path.Join(homePath, ".hugo-wrapper")
-	if _, err := os.Stat(hugoVersionManagerPath); err != nil {
-		fmt.Println("creation of ~/.hugo-wrapper")
-		os.Mkdir(hugoVersionManagerPath, 0770)
+	location, err := resolveInstallLocation(config)
+	if err != nil {
+		return nil, err
+	}
+	if err = prepareInstallLocation(location); err != nil {
+		return nil, err
+	}
+	if backendList, found := os.LookupEnv(backendsEnv); found {
+		backendsKey, _ := versionmanager.LookupConfigKey("backends")
+		if err := backendsKey.Set(config, backendList); err != nil {
+			return nil, fmt.Errorf("%s: %w", backendsEnv, err)
+		}
+	}
+	configOptions, err := config.Options(os.Stderr)
+	if err != nil {
+		return nil, err
 	}
 
-	versionManager, err := versionmanager.NewVersionManager(hugoVersionManagerPath)
+	defaults := append(configOptions,
+		versionmanager.WithInstallDirectory(location.install),
+		versionmanager.WithCacheDirectory(location.cache),
+	)
+	if days, found := os.LookupEnv(minReleaseAgeEnv); found {
+		age, err := strconv.Atoi(days)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number of days: %w", minReleaseAgeEnv, err)
+		}
+		defaults = append(defaults, versionmanager.WithMinReleaseAge(time.Duration(age)*24*time.Hour))
+	}
+	if value, found := os.LookupEnv(previousMinorEnv); found {
+		previousMinor, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", previousMinorEnv, err)
+		}
+		defaults = append(defaults, versionmanager.WithPreviousMinor(previousMinor))
+	}
+	options = append(defaults, options...)
+	manager, err := versionmanager.New(options...)
 	if err != nil {
 		return nil, err
 	}
+	managers = append(managers, manager)
+	return manager, nil
+}
+
+// applyRetention removes the versions beyond the retention, keeping the ones
+// used by the command and the one pinned by the project.
+func applyRetention() {
+	if len(managers) == 0 {
+		return
+	}
+	protected := []string{}
+	workingDirectory, _ := os.Getwd()
+	if resolution, err := versionmanager.ResolveSpec(projectSources(workingDirectory)...); err == nil {
+		protected = append(protected, resolution.Spec)
+	}
+	for _, manager := range managers {
+		if err := manager.ApplyRetention(protected...); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: the versions beyond the retention can't be removed: %s\n", err)
+		}
+	}
+}
+
+// specSources lists, by order of precedence, the places where the hugo version can be declared.
+func specSources(cmd *cobra.Command) []versionmanager.SpecSource {
+	workingDirectory, _ := os.Getwd()
+	sources := []versionmanager.SpecSource{
+		versionmanager.NewValueSource("flag --hugo-version", hugoVersion, cmd.Flags().Changed("hugo-version")),
+		versionmanager.NewEnvSource(hugoVersionEnv),
+	}
+	sources = append(sources, projectSources(workingDirectory)...)
+	return append(sources,
+		globalDefaultSource(),
+		versionmanager.NewValueSource("default", cmd.Flags().Lookup("hugo-version").DefValue, true),
+	)
+}
+
+// projectSources lists, by order of precedence, the files of the project declaring the hugo version.
+func projectSources(workingDirectory string) []versionmanager.SpecSource {
+	return []versionmanager.SpecSource{
+		versionmanager.NewPinFileSource(workingDirectory),
+		versionmanager.NewToolVersionsSource(workingDirectory),
+		versionmanager.NewPackageJSONSource(workingDirectory),
+		versionmanager.NewNetlifySource(workingDirectory),
+		versionmanager.NewSiteConfigSource(siteLocation(workingDirectory)),
+	}
+}
+
+// globalDefaultSource returns the source of the default version of the
+// configuration, set with use --global or in the configuration of the project.
+func globalDefaultSource() versionmanager.SpecSource {
+	files, err := loadConfigFiles()
+	if err != nil {
+		return versionmanager.NewValueSource("global default", "", false)
+	}
+	name := "global default " + files.userPath
+	if files.project.DefaultVersion != "" || files.project.DefaultEdition != "" {
+		name = "project default " + files.projectPath
+	}
+	return versionmanager.NewConfigSource(name, files.merged())
+}
+
+// siteLocation returns the directory of the site built by hugo and its
+// configuration files, given by the --source and --config flags passed through to hugo.
+func siteLocation(workingDirectory string) (sourceDirectory string, configFiles []string) {
+	sourceDirectory = workingDirectory
+	if source, found := wrappedFlagValue("source", "s"); found {
+		sourceDirectory = source
+		if !filepath.IsAbs(source) {
+			sourceDirectory = filepath.Join(workingDirectory, source)
+		}
+	}
+	configFiles = []string{}
+	if config, found := wrappedFlagValue("config"); found {
+		for _, configFile := range strings.Split(config, ",") {
+			if configFile = strings.TrimSpace(configFile); configFile != "" {
+				configFiles = append(configFiles, configFile)
+			}
+		}
+	}
+	return sourceDirectory, configFiles
+}
+
+func wrappedFlagValue(names ...string) (string, bool) {
+	for _, flag := range wrappedFlags {
+		for _, name := range names {
+			if flag.Name == name {
+				return flag.Value.String(), true
+			}
+		}
+	}
+	return "", false
+}
 
-	command := new(exec.Cmd)
-	path, selectedVersion, err := versionManager.GetExecPath(hugoVersion)
+func isDetectionEnabled(cmd *cobra.Command) (bool, error) {
+	if cmd.Flags().Changed("hugo-detect-extended") {
+		return detectExtended, nil
+	}
+	if value, found := os.LookupEnv(detectExtendedEnv); found {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", detectExtendedEnv, err)
+		}
+		return enabled, nil
+	}
+	return detectExtended, nil
+}
+
+// resolveSpec selects the spec, combined with the requirements of the themes and modules of the site.
+func resolveSpec(cmd *cobra.Command) (*versionmanager.SpecResolution, error) {
+	resolution, err := versionmanager.ResolveSpec(specSources(cmd)...)
+	if err != nil {
+		return nil, err
+	}
+	workingDirectory, _ := os.Getwd()
+	sourceDirectory, configFiles := siteLocation(workingDirectory)
+	if err = resolution.ResolveCommitDate(sourceDirectory); err != nil {
+		return nil, err
+	}
+	detectionEnabled, err := isDetectionEnabled(cmd)
 	if err != nil {
 		return nil, err
 	}
-	command.Path = path
-	fmt.Printf("selected version: %s\n", selectedVersion)
-	command.Args = []string{"hugo"}
-	command.Args = append(command.Args, args...)
+	if detectionEnabled {
+		reason, required, err := versionmanager.DetectExtended(sourceDirectory)
+		if err != nil {
+			return nil, err
+		}
+		if required && resolution.RequireExtended(reason) {
+			fmt.Fprintf(os.Stderr, "extended edition selected: %s, disable with --hugo-detect-extended=false\n", reason)
+		}
+	}
+	requirements, err := versionmanager.ComponentRequirements(sourceDirectory, configFiles)
+	if err != nil {
+		return nil, err
+	}
+	if err = resolution.Constrain(requirements...); err != nil {
+		return nil, err
+	}
+	return resolution, nil
+}
 
-	return command, nil
+func wrapHugo(cmd *cobra.Command, args []string) error {
+	manager, err := newManager(versionmanager.WithObserver(versionmanager.NewCLIObserver(os.Stderr)))
+	if err != nil {
+		return err
+	}
+	resolution, err := resolveSpec(cmd)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	version, err := manager.Resolve(ctx, resolution.Spec)
+	if err != nil {
+		return resolution.ExplainError(err)
+	}
+	if notify, _ := strconv.ParseBool(os.Getenv(notifyEnv)); notify {
+		// the notice must never prevent the build
+		if notice, err := manager.NewerPatchNotice(ctx, version); err == nil && notice != "" {
+			fmt.Fprintln(os.Stderr, notice)
+		}
+	}
+	return manager.Run(ctx, version, args)
 }
 
-func persistantPostRun(cmd *cobra.Command, args []string) {
+func runHugo(cmd *cobra.Command, args []string) error {
 	for _, flag := range wrappedFlags {
 		stringFlag := "-" + flag.Name
 		if len(flag.Name) > 1 {
@@ -119,13 +337,5 @@ func persistantPostRun(cmd *cobra.Command, args []string) {
 			wrappedArgs = append(wrappedArgs, flag.Value.String())
 		}
 	}
-	command, err := getHugoCommand(wrappedArgs)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	command.Stdout = os.Stdout
-	command.Stderr = os.Stderr
-	command.Stdin = os.Stdin
-	command.Run()
+	return wrapHugo(cmd, wrappedArgs)
 }
@@ -0,0 +1,184 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+// wrapperVersion is the tag the wrapper has been released with, set at build time with
+// -ldflags "-X github.com/TiboStev/hugo-wrapper/cmd.wrapperVersion=<tag>".
+var wrapperVersion = "dev"
+
+var selfUpdateRollback bool
+var selfUpdateInsecure bool
+var selfUpdateForce bool
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Replace the wrapper with its latest release",
+	Long: `Download the latest release of the wrapper for the current platform, when it is newer than the
+running one, check it against the checksums published with the release and replace the running
+executable with it, the previous one being kept as a backup next to it. --force installs the latest
+release even when it isn't newer, or when the running version is a development build. --rollback
+restores the backup.`,
+	Args: cobra.NoArgs,
+	RunE: runSelfUpdate,
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateRollback, "rollback", false, "restore the executable replaced by the last update")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateInsecure, "insecure", false, "install a release publishing no checksum of its executable")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateForce, "force", false, "install the latest release even when it isn't newer than the running one")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return err
+	}
+	backup := executable + ".backup"
+	if selfUpdateRollback {
+		return rollbackExecutable(cmd, executable, backup)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repository, downloadClient, err := config.WrapperClients()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	release, err := versionmanager.LatestWrapperRelease(ctx, repository, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	newer, err := release.IsNewerThan(wrapperVersion)
+	if err != nil && !selfUpdateForce {
+		return fmt.Errorf("%w, --force installs it anyway", err)
+	}
+	if err == nil && !newer && !selfUpdateForce {
+		fmt.Fprintf(cmd.OutOrStdout(), "hugo-wrapper %s is up to date, the latest release being %s\n", wrapperVersion, release.Version)
+		return nil
+	}
+
+	// the new executable is written next to the current one, so that it can be renamed over it
+	update := executable + ".new"
+	verified, err := release.Download(ctx, downloadClient, update)
+	if err != nil {
+		os.Remove(update)
+		return err
+	}
+	if !verified {
+		if !selfUpdateInsecure {
+			os.Remove(update)
+			return fmt.Errorf("the release %s publishes no checksum of its executable, --insecure installs it anyway", release.Version)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: the release %s publishes no checksum of its executable, only its size has been checked\n", release.Version)
+	}
+	if err := exec.Command(update, "--help").Run(); err != nil {
+		os.Remove(update)
+		return fmt.Errorf("the executable of %s doesn't run: %w", release.Version, err)
+	}
+	if err := replaceExecutable(executable, update, backup); err != nil {
+		os.Remove(update)
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "hugo-wrapper updated from %s to %s, the previous executable is kept in %s\n", wrapperVersion, release.Version, backup)
+	return nil
+}
+
+// replaceExecutable keeps the executable in backup and puts update in its place.
+// On POSIX a single rename replaces it atomically, windows can't rename over a
+// running executable which is moved to backup first.
+func replaceExecutable(executable string, update string, backup string) error {
+	if runtime.GOOS != "windows" {
+		if err := keepCopy(executable, backup); err != nil {
+			return err
+		}
+		return os.Rename(update, executable)
+	}
+	os.Remove(backup)
+	if err := os.Rename(executable, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(update, executable); err != nil {
+		if restoreErr := os.Rename(backup, executable); restoreErr != nil {
+			return fmt.Errorf("%w, and the previous executable can't be restored from %s: %s", err, backup, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// keepCopy hard links path to copyPath, or copies it when the filesystem has no hard links.
+func keepCopy(path string, copyPath string) error {
+	os.Remove(copyPath)
+	if err := os.Link(path, copyPath); err == nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	input, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	output, err := os.OpenFile(copyPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(output, input); err != nil {
+		output.Close()
+		os.Remove(copyPath)
+		return err
+	}
+	return output.Close()
+}
+
+// rollbackExecutable swaps the executable with its backup, so that a rollback can be undone the same way.
+func rollbackExecutable(cmd *cobra.Command, executable string, backup string) error {
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("no backup to restore: %w", err)
+	}
+	restored := executable + ".new"
+	if err := os.Rename(backup, restored); err != nil {
+		return err
+	}
+	if err := replaceExecutable(executable, restored, backup); err != nil {
+		os.Rename(restored, backup)
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "hugo-wrapper restored from %s\n", backup)
+	return nil
+}
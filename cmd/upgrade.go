@@ -0,0 +1,101 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/TiboStev/hugo-wrapper/sitediff"
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var upgradeDryRun bool
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [hugo-version]",
+	Short: "Pin a newer hugo version in every file pinning it",
+	Long: `Resolve the target version, by default the newest release allowed by the requirements of the
+site, its themes and modules, and replace the pinned version in the closest .hugo-version,
+.tool-versions, package.json and netlify.toml, and in the GitHub workflows, up to the root of the
+repository, or in the current directory only outside of a repository. The formatting and
+the editions of the files are kept. The changes are shown as a diff, --dry-run only shows them.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUpgrade,
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "show the changes without writing them")
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	sourceDirectory, configFiles := siteLocation(workingDirectory)
+	requirements, err := versionmanager.SiteRequirements(sourceDirectory, configFiles)
+	if err != nil {
+		return err
+	}
+	componentRequirements, err := versionmanager.ComponentRequirements(sourceDirectory, configFiles)
+	if err != nil {
+		return err
+	}
+	requirements = append(requirements, componentRequirements...)
+	resolution := &versionmanager.SpecResolution{Spec: "latest", Source: "upgrade target"}
+	if len(args) > 0 {
+		resolution.Spec = args[0]
+	}
+	if err = resolution.ResolveCommitDate(sourceDirectory); err != nil {
+		return err
+	}
+	if err = resolution.Constrain(requirements...); err != nil {
+		return err
+	}
+	version, err := manager.Resolve(context.Background(), resolution.Spec)
+	if err != nil {
+		return resolution.ExplainError(err)
+	}
+	updates, err := versionmanager.PlanUpgrade(workingDirectory, version.Value())
+	if err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		fmt.Printf("no file pins a version other than %s\n", version)
+		return nil
+	}
+	for _, update := range updates {
+		fmt.Print(sitediff.UnifiedDiff(update.Path, update.Path, update.Before, update.After, 3))
+	}
+	if upgradeDryRun {
+		return nil
+	}
+	for _, update := range updates {
+		if err = update.Apply(); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%d files now pin %s\n", len(updates), version)
+	return nil
+}
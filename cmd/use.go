@@ -0,0 +1,104 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TiboStev/hugo-wrapper/versionmanager"
+	"github.com/spf13/cobra"
+)
+
+var global bool
+
+// installNow installs the version set by use or local without asking.
+var installNow bool
+
+var useCmd = &cobra.Command{
+	Use:   "use <hugo-version> --global",
+	Short: "Set the hugo version used by default",
+	Long: `Set the hugo version used when no other source declares one, in the configuration of the wrapper.
+The version is resolved before being saved, and installed right away when accepted.
+Use the local command to pin the version of a project.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUse,
+}
+
+func init() {
+	useCmd.Flags().BoolVar(&global, "global", false, "set the user-wide default version")
+	useCmd.Flags().BoolVar(&installNow, "install", false, "install the version without asking")
+	rootCmd.AddCommand(useCmd)
+}
+
+func runUse(cmd *cobra.Command, args []string) error {
+	if !global {
+		return errors.New("use sets the default version with --global, the local command pins the version of a project")
+	}
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	spec := args[0]
+	version, err := validateSpec(manager, spec)
+	if err != nil {
+		return err
+	}
+	config, path, _, err := readUserConfig()
+	if err != nil {
+		return err
+	}
+	config.DefaultVersion = spec
+	if err = config.Write(path); err != nil {
+		return err
+	}
+	fmt.Printf("default version set to %s (currently %s) in %s\n", spec, version, path)
+	return offerInstall(manager, version)
+}
+
+// validateSpec resolves spec, failing when no release matches it.
+func validateSpec(manager *versionmanager.Manager, spec string) (*versionmanager.Version, error) {
+	workingDirectory, _ := os.Getwd()
+	resolution := &versionmanager.SpecResolution{Spec: spec}
+	if err := resolution.ResolveCommitDate(workingDirectory); err != nil {
+		return nil, err
+	}
+	return manager.Resolve(context.Background(), resolution.Spec)
+}
+
+// offerInstall installs version when --install is set, or when the user accepts it in a terminal.
+func offerInstall(manager *versionmanager.Manager, version *versionmanager.Version) error {
+	if !installNow {
+		if !isTerminal(os.Stdin) {
+			return nil
+		}
+		fmt.Printf("install %s now? [y/N] ", version)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return nil
+		}
+	}
+	execPath, err := manager.Install(context.Background(), version)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s installed in %s\n", version, execPath)
+	return nil
+}
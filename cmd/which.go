@@ -0,0 +1,61 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "Print the path of the hugo executable that would be run",
+	Long: `Print the absolute path of the hugo executable that would be run for the selected version.
+The version is resolved but not installed.`,
+	Args: cobra.NoArgs,
+	RunE: runWhich,
+}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+}
+
+func runWhich(cmd *cobra.Command, args []string) error {
+	manager, err := newManager()
+	if err != nil {
+		return err
+	}
+	resolution, err := resolveSpec(cmd)
+	if err != nil {
+		return err
+	}
+	execPath, installed, err := manager.Which(resolution.Spec)
+	if err != nil {
+		return resolution.ExplainError(err)
+	}
+	execPath, err = filepath.Abs(execPath)
+	if err != nil {
+		return err
+	}
+	fmt.Println(execPath)
+	if !installed {
+		fmt.Fprintln(os.Stderr, "not installed yet, it will be installed on the next run")
+	}
+	return nil
+}
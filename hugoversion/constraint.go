@@ -0,0 +1,139 @@
+package hugoversion
+
+import (
+	"strings"
+)
+
+// Comparison is a version compared with an operator, such as >=0.72.0.
+type Comparison struct {
+	Operator string
+	Version  Version
+}
+
+// Constraint is satisfied by the versions satisfying all its comparisons.
+type Constraint []Comparison
+
+var operators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// IsConstraint tells if text is a constraint rather than a version.
+func IsConstraint(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, operator := range operators {
+		if strings.HasPrefix(text, operator) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConstraint reads comparisons separated by commas, such as >=0.72,<0.80.
+// The missing patch of a version is 0, and its edition is ignored.
+func ParseConstraint(text string) (Constraint, error) {
+	constraint := Constraint{}
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		comparison := Comparison{}
+		for _, operator := range operators {
+			if strings.HasPrefix(item, operator) {
+				comparison.Operator = operator
+				break
+			}
+		}
+		if comparison.Operator == "" {
+			return nil, &SyntaxError{Text: text, Reason: "expected an operator among " + strings.Join(operators, " ")}
+		}
+		version, err := Parse(strings.TrimPrefix(item, comparison.Operator))
+		if err != nil {
+			return nil, &SyntaxError{Text: text, Reason: err.(*SyntaxError).Reason}
+		}
+		comparison.Version = version
+		constraint = append(constraint, comparison)
+	}
+	return constraint, nil
+}
+
+func (comparison Comparison) Check(version Version) bool {
+	result := version.Compare(comparison.Version)
+	switch comparison.Operator {
+	case ">=":
+		return result >= 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case "<":
+		return result < 0
+	case "!=":
+		return result != 0
+	}
+	return result == 0
+}
+
+func (comparison Comparison) String() string {
+	version := comparison.Version
+	version.Edition = Standard
+	return comparison.Operator + strings.TrimPrefix(version.String(), "v")
+}
+
+func (constraint Constraint) Check(version Version) bool {
+	for _, comparison := range constraint {
+		if !comparison.Check(version) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect returns the constraint satisfied by the versions satisfying both constraints.
+func (constraint Constraint) Intersect(other Constraint) Constraint {
+	intersection := make(Constraint, 0, len(constraint)+len(other))
+	intersection = append(intersection, constraint...)
+	return append(intersection, other...)
+}
+
+func (constraint Constraint) String() string {
+	comparisons := make([]string, len(constraint))
+	for i, comparison := range constraint {
+		comparisons[i] = comparison.String()
+	}
+	return strings.Join(comparisons, ",")
+}
+
+// Satisfiable tells if a version may satisfy the constraint, whether it has
+// been released or not.
+func (constraint Constraint) Satisfiable() bool {
+	var lower, upper *Comparison
+	for i := range constraint {
+		comparison := &constraint[i]
+		switch comparison.Operator {
+		case ">=", ">":
+			lower = tighter(lower, comparison, 1)
+		case "<=", "<":
+			upper = tighter(upper, comparison, -1)
+		case "=":
+			lower = tighter(lower, &Comparison{Operator: ">=", Version: comparison.Version}, 1)
+			upper = tighter(upper, &Comparison{Operator: "<=", Version: comparison.Version}, -1)
+		}
+	}
+	if lower == nil || upper == nil {
+		return true
+	}
+	result := lower.Version.Compare(upper.Version)
+	if result != 0 {
+		return result < 0
+	}
+	return lower.Operator == ">=" && upper.Operator == "<=" && constraint.Check(lower.Version)
+}
+
+// tighter returns the most restrictive of two bounds, direction being 1 for
+// the lower bounds and -1 for the upper ones.
+func tighter(bound *Comparison, other *Comparison, direction int) *Comparison {
+	if bound == nil {
+		return other
+	}
+	result := other.Version.Compare(bound.Version) * direction
+	if result > 0 || (result == 0 && len(other.Operator) == 1) {
+		return other
+	}
+	return bound
+}
@@ -0,0 +1,49 @@
+package hugoversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraint(t *testing.T) {
+	assert := assert.New(t)
+
+	constraint, err := ParseConstraint(">=0.72, <=0.80.1")
+	assert.Nil(err)
+	assert.Equal(">=0.72.0,<=0.80.1", constraint.String())
+	assert.True(constraint.Check(MustParse("0.72.0")))
+	assert.True(constraint.Check(MustParse("0.80.1-extended")), "the edition should be ignored")
+	assert.False(constraint.Check(MustParse("0.71.1")))
+	assert.False(constraint.Check(MustParse("0.80.2")))
+
+	other, _ := ParseConstraint("!=0.75.0")
+	assert.False(constraint.Intersect(other).Check(MustParse("0.75.0")))
+	assert.Equal(2, len(constraint), "the intersection shouldn't modify the constraint")
+
+	assert.True(IsConstraint(" >0.72"))
+	assert.False(IsConstraint("0.72"))
+	for _, invalid := range []string{"", "0.72", ">=0.72,", "=>0.72", ">=latest"} {
+		_, err := ParseConstraint(invalid)
+		assert.NotNil(err, "%q should be invalid", invalid)
+	}
+}
+
+func TestSatisfiable(t *testing.T) {
+	assert := assert.New(t)
+	for text, satisfiable := range map[string]bool{
+		">=0.72":                true,
+		">=0.72,<=0.72":         true,
+		">=0.72,<0.72":          false,
+		">0.80,<0.90,>=0.85":    true,
+		">=0.85,<=0.80.1":       false,
+		"=0.72.3,>=0.70":        true,
+		"=0.72.3,>=0.84":        false,
+		"=0.72.3,!=0.72.3":      false,
+		">0.72.3,<=0.72.3,>0.1": false,
+	} {
+		constraint, err := ParseConstraint(text)
+		assert.Nil(err)
+		assert.Equal(satisfiable, constraint.Satisfiable(), text)
+	}
+}
@@ -0,0 +1,252 @@
+// Package hugoversion parses and orders hugo versions. It has no side effect:
+// versions are never resolved against the published releases.
+package hugoversion
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Edition int
+
+const (
+	Standard = Edition(iota)
+	Extended
+	ExtendedWithDeploy
+)
+
+var editionNames = []string{"standard", "extended", "extended_withdeploy"}
+
+func (edition Edition) String() string {
+	if edition < Standard || edition > ExtendedWithDeploy {
+		return fmt.Sprintf("Edition(%d)", int(edition))
+	}
+	return editionNames[edition]
+}
+
+// ParseEdition accepts the names returned by Edition.String, and withdeploy
+// as a short form of extended_withdeploy.
+func ParseEdition(name string) (Edition, error) {
+	switch strings.ToLower(name) {
+	case "", "standard":
+		return Standard, nil
+	case "extended":
+		return Extended, nil
+	case "extended_withdeploy", "withdeploy":
+		return ExtendedWithDeploy, nil
+	}
+	return Standard, &SyntaxError{Text: name, Reason: "unknown edition"}
+}
+
+// editionSuffixes are tried in order, the longest first.
+var editionSuffixes = []struct {
+	suffix  string
+	edition Edition
+}{
+	{"-extended_withdeploy", ExtendedWithDeploy},
+	{"-withdeploy", ExtendedWithDeploy},
+	{"-extended", Extended},
+}
+
+// Version is a hugo version, following semantic versioning with an edition.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Build      string
+	Edition    Edition
+}
+
+type SyntaxError struct {
+	Text   string
+	Reason string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid version %q: %s", err.Text, err.Reason)
+}
+
+// Parse reads a version in the form of
+// [v]major.minor[.patch][-prerelease][+build][-extended|-extended_withdeploy].
+// The patch may be omitted, as it is in the tags of the releases prior to 0.54.
+func Parse(text string) (Version, error) {
+	version := Version{}
+	remaining := strings.TrimPrefix(strings.TrimSpace(text), "v")
+	for _, candidate := range editionSuffixes {
+		if strings.HasSuffix(remaining, candidate.suffix) {
+			remaining = strings.TrimSuffix(remaining, candidate.suffix)
+			version.Edition = candidate.edition
+			break
+		}
+	}
+	if index := strings.Index(remaining, "+"); index >= 0 {
+		version.Build = remaining[index+1:]
+		remaining = remaining[:index]
+		if !validIdentifiers(version.Build) {
+			return Version{}, &SyntaxError{Text: text, Reason: "invalid build metadata"}
+		}
+	}
+	if index := strings.Index(remaining, "-"); index >= 0 {
+		version.Prerelease = remaining[index+1:]
+		remaining = remaining[:index]
+		if !validIdentifiers(version.Prerelease) {
+			return Version{}, &SyntaxError{Text: text, Reason: "invalid prerelease"}
+		}
+	}
+	identifiers := strings.Split(remaining, ".")
+	if len(identifiers) < 2 || len(identifiers) > 3 {
+		return Version{}, &SyntaxError{Text: text, Reason: "expected major.minor[.patch]"}
+	}
+	numbers := make([]int, 3)
+	for i, identifier := range identifiers {
+		number, err := strconv.Atoi(identifier)
+		if err != nil || number < 0 {
+			return Version{}, &SyntaxError{Text: text, Reason: fmt.Sprintf("%q is not a version number", identifier)}
+		}
+		numbers[i] = number
+	}
+	version.Major, version.Minor, version.Patch = numbers[0], numbers[1], numbers[2]
+	return version, nil
+}
+
+// MustParse is like Parse but panics on invalid versions, it is meant for constants.
+func MustParse(text string) Version {
+	version, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return version
+}
+
+func validIdentifiers(identifiers string) bool {
+	for _, identifier := range strings.Split(identifiers, ".") {
+		if identifier == "" {
+			return false
+		}
+		for _, character := range identifier {
+			isAlphanumeric := (character >= '0' && character <= '9') || (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z')
+			if !isAlphanumeric && character != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (version Version) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "v%d.%d.%d", version.Major, version.Minor, version.Patch)
+	if version.Prerelease != "" {
+		builder.WriteString("-" + version.Prerelease)
+	}
+	if version.Build != "" {
+		builder.WriteString("+" + version.Build)
+	}
+	if version.Edition != Standard {
+		builder.WriteString("-" + version.Edition.String())
+	}
+	return builder.String()
+}
+
+// Compare returns -1, 0 or 1 when version is lower, equal or higher than other.
+// As in semantic versioning, the build metadata is ignored, and so is the edition.
+func (version Version) Compare(other Version) int {
+	for _, difference := range []int{version.Major - other.Major, version.Minor - other.Minor, version.Patch - other.Patch} {
+		if difference != 0 {
+			return sign(difference)
+		}
+	}
+	return comparePrerelease(version.Prerelease, other.Prerelease)
+}
+
+func Compare(a Version, b Version) int {
+	return a.Compare(b)
+}
+
+func (version Version) Less(other Version) bool {
+	return version.Compare(other) < 0
+}
+
+// comparePrerelease orders the prerelease identifiers, a version without
+// prerelease has a higher precedence than one with.
+func comparePrerelease(prerelease string, other string) int {
+	switch {
+	case prerelease == other:
+		return 0
+	case prerelease == "":
+		return 1
+	case other == "":
+		return -1
+	}
+	identifiers := strings.Split(prerelease, ".")
+	otherIdentifiers := strings.Split(other, ".")
+	for i := 0; i < len(identifiers) && i < len(otherIdentifiers); i++ {
+		number, err := strconv.Atoi(identifiers[i])
+		isNumeric := err == nil
+		otherNumber, err := strconv.Atoi(otherIdentifiers[i])
+		otherIsNumeric := err == nil
+		switch {
+		case isNumeric && otherIsNumeric:
+			if number != otherNumber {
+				return sign(number - otherNumber)
+			}
+		case isNumeric:
+			return -1
+		case otherIsNumeric:
+			return 1
+		default:
+			if comparison := strings.Compare(identifiers[i], otherIdentifiers[i]); comparison != 0 {
+				return comparison
+			}
+		}
+	}
+	return sign(len(identifiers) - len(otherIdentifiers))
+}
+
+func sign(number int) int {
+	switch {
+	case number < 0:
+		return -1
+	case number > 0:
+		return 1
+	}
+	return 0
+}
+
+func (version Version) MarshalText() ([]byte, error) {
+	return []byte(version.String()), nil
+}
+
+func (version *Version) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*version = parsed
+	return nil
+}
+
+// Versions sorts in ascending order.
+type Versions []Version
+
+func (versions Versions) Len() int           { return len(versions) }
+func (versions Versions) Less(i, j int) bool { return versions[i].Less(versions[j]) }
+func (versions Versions) Swap(i, j int)      { versions[i], versions[j] = versions[j], versions[i] }
+
+// Sort sorts versions in ascending order, keeping the order of the equal ones.
+func Sort(versions []Version) {
+	sort.Stable(Versions(versions))
+}
+
+// Latest returns the highest of versions, ok is false when there is none.
+func Latest(versions []Version) (latest Version, ok bool) {
+	for i, version := range versions {
+		if i == 0 || latest.Less(version) {
+			latest = version
+		}
+	}
+	return latest, len(versions) > 0
+}
@@ -0,0 +1,72 @@
+package hugoversion
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	version, err := Parse("v0.72.3-extended")
+	assert.Nil(err)
+	assert.Equal(Version{Major: 0, Minor: 72, Patch: 3, Edition: Extended}, version)
+
+	version, err = Parse("0.53")
+	assert.Nil(err)
+	assert.Equal(Version{Major: 0, Minor: 53}, version, "the patch can be omitted")
+
+	version, err = Parse("0.137.0-rc.1+build.5-extended_withdeploy")
+	assert.Nil(err)
+	assert.Equal(Version{Major: 0, Minor: 137, Prerelease: "rc.1", Build: "build.5", Edition: ExtendedWithDeploy}, version)
+	assert.Equal("v0.137.0-rc.1+build.5-extended_withdeploy", version.String())
+
+	version, err = Parse("0.137.0-withdeploy")
+	assert.Nil(err)
+	assert.Equal(ExtendedWithDeploy, version.Edition)
+
+	for _, invalid := range []string{"", "latest", "1", "1.2.3.4", "1.x.3", "1.2.-3", "1.2.3-", "1.2.3-a..b", "1.2.3+"} {
+		_, err := Parse(invalid)
+		assert.NotNil(err, "%q should be invalid", invalid)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	assert := assert.New(t)
+	ordered := []string{"0.9.0", "0.53", "0.53.1", "0.120.0-alpha", "0.120.0-alpha.1", "0.120.0-alpha.beta", "0.120.0-beta.2", "0.120.0-beta.11", "0.120.0-rc.1", "0.120.0", "1.0.0"}
+	for i := 0; i+1 < len(ordered); i++ {
+		lower, higher := MustParse(ordered[i]), MustParse(ordered[i+1])
+		assert.Equal(-1, lower.Compare(higher), "%s should be lower than %s", lower, higher)
+		assert.Equal(1, higher.Compare(lower), "%s should be higher than %s", higher, lower)
+	}
+	assert.Equal(0, MustParse("0.72.0+a").Compare(MustParse("0.72.0+b-extended")), "build metadata and edition are ignored")
+}
+
+func TestSort(t *testing.T) {
+	versions := []Version{MustParse("0.72.1"), MustParse("0.9.0"), MustParse("0.72.0-extended"), MustParse("0.72.0")}
+	Sort(versions)
+	assert.Equal(t, []Version{MustParse("0.9.0"), MustParse("0.72.0-extended"), MustParse("0.72.0"), MustParse("0.72.1")}, versions)
+
+	latest, ok := Latest(versions)
+	assert.True(t, ok)
+	assert.Equal(t, MustParse("0.72.1"), latest)
+	_, ok = Latest(nil)
+	assert.False(t, ok)
+}
+
+func TestText(t *testing.T) {
+	assert := assert.New(t)
+	var config struct {
+		Version Version `json:"version"`
+	}
+	assert.Nil(json.Unmarshal([]byte(`{"version": "0.72.3-extended"}`), &config))
+	assert.Equal(Version{Major: 0, Minor: 72, Patch: 3, Edition: Extended}, config.Version)
+
+	encoded, err := json.Marshal(config)
+	assert.Nil(err)
+	assert.Equal(`{"version":"v0.72.3-extended"}`, string(encoded))
+
+	assert.NotNil(json.Unmarshal([]byte(`{"version": "latest"}`), &config))
+}
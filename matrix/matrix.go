@@ -0,0 +1,273 @@
+// Package matrix builds a site with several hugo versions, each into its own
+// directory, and reports the outcome of every build.
+package matrix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// workDirectory is the directory of the destination holding the resources and
+// the cache of each version.
+const workDirectory = ".hugo-wrapper"
+
+// Build is the build of the site with a version.
+type Build struct {
+	Spec        string  `json:"spec"`
+	Version     string  `json:"version,omitempty"`
+	Success     bool    `json:"success"`
+	Duration    float64 `json:"duration_seconds"`
+	Warnings    int     `json:"warnings"`
+	OutputSize  int64   `json:"output_size"`
+	Destination string  `json:"destination,omitempty"`
+	Error       string  `json:"error,omitempty"`
+	// ExecPath is the hugo executable of the version.
+	ExecPath string `json:"-"`
+	// WorkDirectory holds the resources and the cache of the build, the ones
+	// of the site being used when it is empty.
+	WorkDirectory string `json:"-"`
+	// Output is what hugo printed.
+	Output string `json:"-"`
+}
+
+// Resolver returns the executable of the version spec resolves to, installing it when needed.
+type Resolver func(spec string) (execPath string, version string, err error)
+
+// Plan resolves the specs one at a time, each version building into its own
+// directory of destination. The specs resolving to a version already planned
+// are reported to log and skipped, the ones failing to resolve are kept with their error.
+func Plan(specs []string, destination string, resolve Resolver, log io.Writer) []*Build {
+	builds := []*Build{}
+	resolved := map[string]bool{}
+	for _, spec := range specs {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		build := &Build{Spec: spec}
+		execPath, version, err := resolve(spec)
+		if err != nil {
+			build.Error = err.Error()
+			builds = append(builds, build)
+			continue
+		}
+		if resolved[version] {
+			fmt.Fprintf(log, "%s resolves to %s, already built\n", spec, version)
+			continue
+		}
+		resolved[version] = true
+		build.Version = version
+		build.Destination = filepath.Join(destination, version)
+		build.WorkDirectory = filepath.Join(destination, workDirectory, version)
+		build.ExecPath = execPath
+		builds = append(builds, build)
+	}
+	return builds
+}
+
+// Run builds the site with hugoArgs into the destination of the build, and
+// records its duration, warnings and output size.
+func (build *Build) Run(hugoArgs []string) {
+	args := append(append([]string{}, hugoArgs...), "--destination", build.Destination)
+	command := exec.Command(build.ExecPath, args...)
+	environment, err := build.environment(sourceDirectory(hugoArgs))
+	if err != nil {
+		build.Error = err.Error()
+		return
+	}
+	command.Env = append(os.Environ(), environment...)
+	var output bytes.Buffer
+	command.Stdout = &output
+	command.Stderr = &output
+	start := time.Now()
+	err = command.Run()
+	build.Duration = time.Since(start).Seconds()
+	build.Output = output.String()
+	build.Warnings = CountWarnings(build.Output)
+	build.OutputSize = directorySize(build.Destination)
+	build.Success = err == nil
+	if err != nil {
+		build.Error = err.Error()
+	}
+}
+
+// environment prepares the resource and cache directories of the build, so that
+// the builds running at the same time don't share the files hugo generates.
+// The resources generated in advance by the site, in resources/_gen, are copied
+// into its resource directory for the editions unable to generate them.
+func (build *Build) environment(siteDirectory string) ([]string, error) {
+	if build.WorkDirectory == "" {
+		return nil, nil
+	}
+	workDirectory, err := filepath.Abs(build.WorkDirectory)
+	if err != nil {
+		return nil, err
+	}
+	resourceDirectory := filepath.Join(workDirectory, "resources")
+	cacheDirectory := filepath.Join(workDirectory, "cache")
+	if err = os.MkdirAll(cacheDirectory, 0755); err != nil {
+		return nil, err
+	}
+	if err = os.RemoveAll(resourceDirectory); err != nil {
+		return nil, err
+	}
+	generated := filepath.Join(siteDirectory, "resources", "_gen")
+	if err = copyDirectory(generated, filepath.Join(resourceDirectory, "_gen")); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return []string{"HUGO_RESOURCEDIR=" + resourceDirectory, "HUGO_CACHEDIR=" + cacheDirectory}, nil
+}
+
+// sourceDirectory returns the directory of the site given to hugo with --source, the working directory by default.
+func sourceDirectory(hugoArgs []string) string {
+	for i, arg := range hugoArgs {
+		for _, flag := range []string{"--source", "-s"} {
+			if arg == flag && i+1 < len(hugoArgs) {
+				return hugoArgs[i+1]
+			}
+			if strings.HasPrefix(arg, flag+"=") {
+				return strings.TrimPrefix(arg, flag+"=")
+			}
+		}
+	}
+	return "."
+}
+
+func copyDirectory(source string, destination string) error {
+	if _, err := os.Stat(source); err != nil {
+		return err
+	}
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destination, relativePath)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, content, info.Mode().Perm())
+	})
+}
+
+// CountWarnings counts the lines of hugo starting with WARN.
+func CountWarnings(output string) int {
+	warnings := 0
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(scanner.Text()), "WARN") {
+			warnings++
+		}
+	}
+	return warnings
+}
+
+func directorySize(directory string) int64 {
+	var size int64
+	filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// WriteTable writes the builds as a table, followed by the output of the failed ones.
+func WriteTable(writer io.Writer, builds []*Build) error {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "SPEC\tVERSION\tSTATUS\tDURATION\tWARNINGS\tSIZE")
+	for _, build := range builds {
+		status := "ok"
+		if !build.Success {
+			status = "failed"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%.1fs\t%d\t%d\n", build.Spec, build.Version, status, build.Duration, build.Warnings, build.OutputSize)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	for _, build := range builds {
+		if !build.Success {
+			if _, err := fmt.Fprintf(writer, "\n%s: %s\n%s", build.Spec, build.Error, build.Output); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the builds as a json array.
+func WriteJSON(writer io.Writer, builds []*Build) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(builds)
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      float64         `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Output  string `xml:",chardata"`
+}
+
+// WriteJUnit writes the builds as a JUnit test suite, a test case per build.
+func WriteJUnit(writer io.Writer, builds []*Build) error {
+	suite := junitTestSuite{Name: "hugo-matrix", Tests: len(builds)}
+	for _, build := range builds {
+		testCase := junitTestCase{ClassName: "hugo-matrix", Name: build.Spec, Time: build.Duration}
+		if build.Version != "" {
+			testCase.Name += " (" + build.Version + ")"
+		}
+		if build.Success {
+			testCase.SystemOut = fmt.Sprintf("%d warnings, %d bytes written to %s", build.Warnings, build.OutputSize, build.Destination)
+		} else {
+			testCase.Failure = &junitFailure{Message: build.Error, Output: build.Output}
+			suite.Failures++
+		}
+		suite.Time += build.Duration
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
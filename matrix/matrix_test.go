@@ -0,0 +1,134 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	assert := assert.New(t)
+
+	versions := map[string]string{"0.115": "0.115.4", "v0.115.4": "0.115.4", "latest": "0.120.0"}
+	resolve := func(spec string) (string, string, error) {
+		version, found := versions[spec]
+		if !found {
+			return "", "", errors.New("no release matches " + spec)
+		}
+		return "/versions/" + version + "/hugo", version, nil
+	}
+	var log bytes.Buffer
+	builds := Plan([]string{"0.115", " v0.115.4", "", "0.99.9", "latest "}, "matrix", resolve, &log)
+
+	assert.Equal([]*Build{
+		{Spec: "0.115", Version: "0.115.4", Destination: filepath.Join("matrix", "0.115.4"),
+			WorkDirectory: filepath.Join("matrix", ".hugo-wrapper", "0.115.4"), ExecPath: "/versions/0.115.4/hugo"},
+		{Spec: "0.99.9", Error: "no release matches 0.99.9"},
+		{Spec: "latest", Version: "0.120.0", Destination: filepath.Join("matrix", "0.120.0"),
+			WorkDirectory: filepath.Join("matrix", ".hugo-wrapper", "0.120.0"), ExecPath: "/versions/0.120.0/hugo"},
+	}, builds)
+	assert.Equal("v0.115.4 resolves to 0.115.4, already built\n", log.String())
+}
+
+func TestCountWarnings(t *testing.T) {
+	assert := assert.New(t)
+
+	output := "Start building sites …\nWARN 2023/06/01 found no layout file\n  WARN  deprecated: .Site.IsServer\nWARNING is not at the start: no\nTotal in 120 ms\n"
+	assert.Equal(3, CountWarnings(output))
+	assert.Equal(0, CountWarnings(""))
+}
+
+func TestEnvironment(t *testing.T) {
+	assert := assert.New(t)
+
+	site, err := ioutil.TempDir("", "matrix-site")
+	assert.NoError(err)
+	defer os.RemoveAll(site)
+	generated := filepath.Join(site, "resources", "_gen", "assets", "scss")
+	assert.NoError(os.MkdirAll(generated, 0755))
+	assert.NoError(ioutil.WriteFile(filepath.Join(generated, "main.css"), []byte("body{}"), 0644))
+
+	destination := filepath.Join(site, "matrix")
+	builds := Plan([]string{"0.115", "0.120"}, destination, func(spec string) (string, string, error) {
+		return "hugo", spec + ".0", nil
+	}, ioutil.Discard)
+	environments := [][]string{}
+	for _, build := range builds {
+		environment, err := build.environment(site)
+		assert.NoError(err)
+		environments = append(environments, environment)
+	}
+
+	workDirectory := filepath.Join(destination, ".hugo-wrapper", "0.115.0")
+	assert.Equal([]string{
+		"HUGO_RESOURCEDIR=" + filepath.Join(workDirectory, "resources"),
+		"HUGO_CACHEDIR=" + filepath.Join(workDirectory, "cache"),
+	}, environments[0])
+	assert.NotEqual(environments[0], environments[1], "each version should have its own directories")
+	content, err := ioutil.ReadFile(filepath.Join(workDirectory, "resources", "_gen", "assets", "scss", "main.css"))
+	assert.NoError(err, "the resources generated by the site should be copied")
+	assert.Equal("body{}", string(content))
+
+	environment, err := builds[0].environment(filepath.Join(site, "missing"))
+	assert.NoError(err, "a site without generated resources should build")
+	assert.Len(environment, 2)
+	environment, err = (&Build{}).environment(site)
+	assert.NoError(err)
+	assert.Nil(environment, "a build without work directory should use the directories of the site")
+}
+
+func TestSourceDirectory(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(".", sourceDirectory([]string{"--minify"}))
+	assert.Equal("site", sourceDirectory([]string{"--minify", "--source", "site"}))
+	assert.Equal("site", sourceDirectory([]string{"-s", "site"}))
+	assert.Equal("site", sourceDirectory([]string{"--source=site"}))
+	assert.Equal(".", sourceDirectory([]string{"--source"}))
+}
+
+func TestWriteJUnit(t *testing.T) {
+	assert := assert.New(t)
+
+	builds := []*Build{
+		{Spec: "0.115", Version: "0.115.4", Success: true, Duration: 1.5, Warnings: 2, OutputSize: 300, Destination: "matrix/0.115.4"},
+		{Spec: "latest", Version: "0.120.0", Duration: 0.5, Error: "exit status 1", Output: "ERROR template: <baseof>"},
+		{Spec: "0.99.9", Error: "no release matches 0.99.9"},
+	}
+	var output bytes.Buffer
+	assert.NoError(WriteJUnit(&output, builds))
+
+	suite := junitTestSuite{}
+	assert.NoError(xml.Unmarshal(output.Bytes(), &suite))
+	assert.Equal("hugo-matrix", suite.Name)
+	assert.Equal(3, suite.Tests)
+	assert.Equal(2, suite.Failures)
+	assert.Equal(2.0, suite.Time)
+	assert.Len(suite.TestCases, 3)
+	assert.Equal("0.115 (0.115.4)", suite.TestCases[0].Name)
+	assert.Nil(suite.TestCases[0].Failure)
+	assert.Equal("2 warnings, 300 bytes written to matrix/0.115.4", suite.TestCases[0].SystemOut)
+	assert.Equal(&junitFailure{Message: "exit status 1", Output: "ERROR template: <baseof>"}, suite.TestCases[1].Failure)
+	assert.Equal("0.99.9", suite.TestCases[2].Name)
+	assert.Equal("no release matches 0.99.9", suite.TestCases[2].Failure.Message)
+}
+
+func TestWriteTable(t *testing.T) {
+	assert := assert.New(t)
+
+	var output bytes.Buffer
+	assert.NoError(WriteTable(&output, []*Build{
+		{Spec: "0.115", Version: "0.115.4", Success: true, Duration: 1.25, Warnings: 2, OutputSize: 300},
+		{Spec: "latest", Version: "0.120.0", Error: "exit status 1", Output: "ERROR build failed\n"},
+	}))
+	assert.Equal("SPEC    VERSION  STATUS  DURATION  WARNINGS  SIZE\n"+
+		"0.115   0.115.4  ok      1.2s      2         300\n"+
+		"latest  0.120.0  failed  0.0s      0         0\n"+
+		"\nlatest: exit status 1\nERROR build failed\n", output.String())
+}
@@ -0,0 +1,150 @@
+package sitediff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the memory used by the longest common subsequence of
+// two files, larger changes being shown as a whole replacement.
+const maxDiffCells = 4 * 1024 * 1024
+
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns the differences between the lines of a and b in the
+// unified format, with context lines around each change.
+func UnifiedDiff(nameA string, nameB string, a string, b string, context int) string {
+	lines := editScript(splitLines(a), splitLines(b))
+	// positions of the lines in a and in b before each line of the script
+	positionsA, positionsB := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for i, line := range lines {
+		positionsA[i+1], positionsB[i+1] = positionsA[i], positionsB[i]
+		if line.kind != '+' {
+			positionsA[i+1]++
+		}
+		if line.kind != '-' {
+			positionsB[i+1]++
+		}
+	}
+
+	var diff strings.Builder
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].kind == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+		if diff.Len() == 0 {
+			fmt.Fprintf(&diff, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		last := i
+		for j := i; j < len(lines) && j-last <= 2*context; j++ {
+			if lines[j].kind != ' ' {
+				last = j
+			}
+		}
+		start, stop := i-context, last+context+1
+		if start < 0 {
+			start = 0
+		}
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+		fmt.Fprintf(&diff, "@@ -%s +%s @@\n",
+			hunkRange(positionsA[start], positionsA[stop]-positionsA[start]),
+			hunkRange(positionsB[start], positionsB[stop]-positionsB[start]))
+		for _, line := range lines[start:stop] {
+			diff.WriteByte(line.kind)
+			diff.WriteString(line.text)
+			diff.WriteByte('\n')
+		}
+		i = stop
+	}
+	return diff.String()
+}
+
+func hunkRange(position int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", position)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", position+1)
+	}
+	return fmt.Sprintf("%d,%d", position+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// editScript turns a into b with the fewest deleted and inserted lines.
+func editScript(a []string, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	lines := make([]diffLine, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	lines = append(lines, middleScript(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	return lines
+}
+
+func middleScript(a []string, b []string) []diffLine {
+	lines := make([]diffLine, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells {
+		for _, text := range a {
+			lines = append(lines, diffLine{'-', text})
+		}
+		for _, text := range b {
+			lines = append(lines, diffLine{'+', text})
+		}
+		return lines
+	}
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,192 @@
+// Package sitediff compares the output of two builds of a site, ignoring the
+// content changing from a build to another such as the generator tag.
+package sitediff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+type ChangeKind string
+
+const (
+	Added   = ChangeKind("added")
+	Removed = ChangeKind("removed")
+	Changed = ChangeKind("changed")
+)
+
+// Change is a file of the output differing between the builds, the sizes
+// being -1 for a file missing from a build.
+type Change struct {
+	Path  string     `json:"path"`
+	Kind  ChangeKind `json:"kind"`
+	SizeA int64      `json:"size_a"`
+	SizeB int64      `json:"size_b"`
+	// Diff is the unified diff of the normalized content of a text file.
+	Diff string `json:"diff,omitempty"`
+}
+
+// SizeDelta is the number of bytes the file gained from the first build to the second.
+func (change Change) SizeDelta() int64 {
+	return maxSize(change.SizeB) - maxSize(change.SizeA)
+}
+
+func maxSize(size int64) int64 {
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+type Report struct {
+	Changes   []Change `json:"changes"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Options tunes the normalization of the content.
+type Options struct {
+	// BuildStart and BuildEnd frame the builds, the timestamps between them
+	// being written at build time rather than taken from the content.
+	BuildStart time.Time
+	BuildEnd   time.Time
+	// Context is the number of lines around the changes of the diffs.
+	Context int
+}
+
+var generatorTag = regexp.MustCompile(`(?i)<meta\s+name=["']?generator["']?\s+content=["']?Hugo[^>]*>`)
+
+var timestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})|[A-Z][a-z]{2}, \d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2} [+-]\d{4}`)
+
+// Normalize removes the generator tag of hugo, and replaces the timestamps of the build.
+func (options Options) Normalize(content []byte) []byte {
+	content = generatorTag.ReplaceAll(content, nil)
+	if options.BuildStart.IsZero() {
+		return content
+	}
+	start, end := options.BuildStart.Add(-time.Minute), options.BuildEnd.Add(time.Minute)
+	return timestamp.ReplaceAllFunc(content, func(match []byte) []byte {
+		for _, layout := range []string{time.RFC3339Nano, time.RFC1123Z} {
+			if date, err := time.Parse(layout, string(match)); err == nil && date.After(start) && date.Before(end) {
+				return []byte("<build timestamp>")
+			}
+		}
+		return match
+	})
+}
+
+// Compare reports the files of directoryB added, removed or changed compared to directoryA.
+func Compare(directoryA string, directoryB string, options Options) (*Report, error) {
+	if options.Context == 0 {
+		options.Context = 3
+	}
+	filesA, err := listFiles(directoryA)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := listFiles(directoryB)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for path := range filesA {
+		paths = append(paths, path)
+	}
+	for path := range filesB {
+		if _, found := filesA[path]; !found {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	report := &Report{Changes: []Change{}}
+	for _, path := range paths {
+		sizeA, inA := filesA[path]
+		sizeB, inB := filesB[path]
+		change := Change{Path: path, SizeA: -1, SizeB: -1}
+		switch {
+		case !inA:
+			change.Kind, change.SizeB = Added, sizeB
+		case !inB:
+			change.Kind, change.SizeA = Removed, sizeA
+		default:
+			contentA, err := ioutil.ReadFile(filepath.Join(directoryA, path))
+			if err != nil {
+				return nil, err
+			}
+			contentB, err := ioutil.ReadFile(filepath.Join(directoryB, path))
+			if err != nil {
+				return nil, err
+			}
+			contentA, contentB = options.Normalize(contentA), options.Normalize(contentB)
+			if bytes.Equal(contentA, contentB) {
+				report.Unchanged++
+				continue
+			}
+			change.Kind, change.SizeA, change.SizeB = Changed, sizeA, sizeB
+			if isText(contentA) && isText(contentB) {
+				change.Diff = UnifiedDiff("a/"+path, "b/"+path, string(contentA), string(contentB), options.Context)
+			}
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	return report, nil
+}
+
+// Hash returns a digest of the paths and normalized content of the files of
+// directory, equal for the builds Compare finds no change between.
+func Hash(directory string, options Options) (string, error) {
+	files, err := listFiles(directory)
+	if err != nil {
+		return "", err
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	hash := sha256.New()
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(filepath.Join(directory, path))
+		if err != nil {
+			return "", err
+		}
+		hash.Write([]byte(path + "\x00"))
+		hash.Write(options.Normalize(content))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// listFiles returns the size of the files of directory, by slash separated relative path.
+func listFiles(directory string) (map[string]int64, error) {
+	files := map[string]int64{}
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relative)] = info.Size()
+		return nil
+	})
+	return files, err
+}
+
+// isText tells if content is UTF-8 text, without the NUL bytes of the binary files.
+func isText(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) < 0 && utf8.Valid(content)
+}
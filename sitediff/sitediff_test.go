@@ -0,0 +1,85 @@
+package sitediff
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	assert.Equal(`--- a
++++ b
+@@ -1,5 +1,5 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+@@ -11,2 +11,3 @@
+ 11
+ 12
++13
+`, UnifiedDiff("a", "b", a, b, 2))
+	assert.Equal("", UnifiedDiff("a", "b", a, a, 3), "identical texts should have no diff")
+	assert.Equal("--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n", UnifiedDiff("a", "b", "", "new\n", 3))
+}
+
+func TestNormalize(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	options := Options{BuildStart: start, BuildEnd: start.Add(10 * time.Second)}
+
+	page := `<meta name="generator" content="Hugo 0.115.0"><time>2023-06-01T12:00:05+02:00</time><time>2021-03-04T10:00:00Z</time>`
+	assert.Equal(`<time><build timestamp></time><time>2021-03-04T10:00:00Z</time>`, string(options.Normalize([]byte(page))),
+		"only the timestamps of the build should be replaced")
+	assert.Equal(`<p>`, string(Options{}.Normalize([]byte(`<META name=generator content="Hugo 0.120.4" /><p>`))))
+}
+
+func TestCompare(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "sitediff")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	write := func(path string, content string) {
+		path = filepath.Join(root, path)
+		assert.Nil(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	write("a/index.html", `<meta name="generator" content="Hugo 0.115.0"><p>same</p>`)
+	write("b/index.html", `<meta name="generator" content="Hugo 0.120.0"><p>same</p>`)
+	write("a/posts/index.html", "<p>old</p>\n")
+	write("b/posts/index.html", "<p>renamed</p>\n")
+	write("a/tags/index.xml", "<rss/>")
+	write("b/logo.png", "\x89PNG\x00")
+
+	report, err := Compare(filepath.Join(root, "a"), filepath.Join(root, "b"), Options{})
+	assert.Nil(err)
+	assert.Equal(1, report.Unchanged, "the generator tag should be ignored")
+	assert.Equal([]Change{
+		{Path: "logo.png", Kind: Added, SizeA: -1, SizeB: 5},
+		{Path: "posts/index.html", Kind: Changed, SizeA: 11, SizeB: 15,
+			Diff: "--- a/posts/index.html\n+++ b/posts/index.html\n@@ -1 +1 @@\n-<p>old</p>\n+<p>renamed</p>\n"},
+		{Path: "tags/index.xml", Kind: Removed, SizeA: 6, SizeB: -1},
+	}, report.Changes)
+	assert.Equal(int64(4), report.Changes[1].SizeDelta())
+
+	hashA, err := Hash(filepath.Join(root, "a"), Options{})
+	assert.Nil(err)
+	hashB, _ := Hash(filepath.Join(root, "b"), Options{})
+	assert.NotEqual(hashA, hashB)
+	write("c/index.html", "<p>same</p>")
+	write("c/posts/index.html", "<p>old</p>\n")
+	write("c/tags/index.xml", "<rss/>")
+	hashC, _ := Hash(filepath.Join(root, "c"), Options{})
+	assert.Equal(hashA, hashC, "the generator tag should be ignored by the hash")
+	assert.Equal(int64(-6), report.Changes[2].SizeDelta())
+}
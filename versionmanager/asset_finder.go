@@ -1,15 +1,27 @@
 package versionmanager
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"strings"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
 )
 
 type assetFinder interface {
-	findLatestVersion() (version *coreVersion, err error)
-	findAssetURL(version *Version) (downloadUrl string, err error)
-	resolveVersion(desiredVersion *coreVersion, compareOn versionPrecision) (*coreVersion, error)
+	findLatestVersion(ctx context.Context) (version *coreVersion, err error)
+	findAsset(ctx context.Context, version *Version) (Asset, error)
+	findAssetURL(ctx context.Context, version *Version) (downloadUrl string, err error)
+	findChecksumsURL(ctx context.Context, version *Version) (downloadUrl string, err error)
+	resolveVersion(ctx context.Context, desiredVersion *coreVersion, compareOn versionPrecision) (*coreVersion, error)
+	resolveConstraint(ctx context.Context, constraint hugoversion.Constraint) (*coreVersion, error)
+	resolveStable(ctx context.Context, constraint hugoversion.Constraint) (*coreVersion, error)
+	resolveDate(ctx context.Context, date time.Time, constraint hugoversion.Constraint) (*coreVersion, error)
+	assetName(version *Version) (string, error)
+	consideredReleases() []string
+	remoteCallCount() int
 }
 
 type finder struct {
@@ -18,77 +30,225 @@ type finder struct {
 	latestVersion         *coreVersion
 	latestSelectedRelease Release
 	latestSelectedVersion *coreVersion
+	candidates            []string
+	remoteCalls           int
+	goos                  string
+	goarch                string
+	minReleaseAge         time.Duration
+	previousMinor         bool
 }
 
+// now is replaced in tests.
+var now = time.Now
+
 func newAssetFinder() (assetFinder assetFinder) {
-	finder := new(finder)
-	finder.repository = NewRepositoryService(Github, "gohugoio", "hugo", "", "")
-	return finder
+	return newPlatformAssetFinder(NewRepositoryService(Github, "gohugoio", "hugo", "", ""), goOS(), goArch())
 }
 
-func (finder *finder) findLatestVersion() (version *coreVersion, err error) {
+func newPlatformAssetFinder(repository RepositoryClient, goos string, goarch string) assetFinder {
+	return &finder{repository: repository, goos: goos, goarch: goarch}
+}
+
+func (finder *finder) findLatestVersion(ctx context.Context) (version *coreVersion, err error) {
 	if finder.latestVersion == nil {
-		finder.latestSelectedRelease, err = finder.repository.GetLatestRelease()
+		finder.latestRelease, err = finder.repository.GetLatestRelease(ctx)
+		finder.remoteCalls++
 		if err != nil {
 			return nil, err
 		}
-		finder.latestSelectedVersion, _, err = parseCoreVersion(finder.latestSelectedRelease.GetName())
+		releaseName := finder.latestRelease.GetName()
+		finder.considered(releaseName, "latest release")
+		finder.latestVersion, _, err = parseCoreVersion(releaseName)
 		if err != nil {
 			return nil, err
 		}
 	}
-	fmt.Printf("%v+", finder.latestSelectedVersion)
-	return finder.latestSelectedVersion, nil
+	finder.latestSelectedRelease, finder.latestSelectedVersion = finder.latestRelease, finder.latestVersion
+	return finder.latestVersion, nil
 }
 
-func (finder *finder) findAssetURL(version *Version) (downloadUrl string, err error) {
-	if finder.latestSelectedVersion == nil || !finder.latestSelectedVersion.Equal(version.coreVersion, patch) {
-		_, err = finder.resolveVersion(version.coreVersion, patch)
-		if err != nil {
-			return "", err
-		}
+func (finder *finder) findAsset(ctx context.Context, version *Version) (Asset, error) {
+	assetName, err := finder.assetName(version)
+	if err != nil {
+		return nil, err
 	}
-	assetName, err := assetName(version)
+	return finder.findReleaseAsset(ctx, version, assetName)
+}
+
+func (finder *finder) findAssetURL(ctx context.Context, version *Version) (downloadUrl string, err error) {
+	asset, err := finder.findAsset(ctx, version)
 	if err != nil {
 		return "", err
 	}
-	asset, err := finder.latestSelectedRelease.GetAssetByName(assetName)
+	return asset.GetDownloadUrl(), nil
+}
+
+func (finder *finder) findChecksumsURL(ctx context.Context, version *Version) (downloadUrl string, err error) {
+	asset, err := finder.findReleaseAsset(ctx, version, checksumsAssetName(version))
 	if err != nil {
 		return "", err
 	}
 	return asset.GetDownloadUrl(), nil
 }
 
-func (finder *finder) resolveVersion(desiredVersion *coreVersion, precision versionPrecision) (*coreVersion, error) {
-	latestVersion, err := finder.findLatestVersion()
+func (finder *finder) findReleaseAsset(ctx context.Context, version *Version, assetName string) (Asset, error) {
+	if finder.latestSelectedVersion == nil || !finder.latestSelectedVersion.Equal(version.coreVersion, patch) {
+		if _, err := finder.resolveVersion(ctx, version.coreVersion, patch); err != nil {
+			return nil, err
+		}
+	}
+	return finder.latestSelectedRelease.GetAssetByName(assetName)
+}
+
+func (finder *finder) resolveVersion(ctx context.Context, desiredVersion *coreVersion, precision versionPrecision) (*coreVersion, error) {
+	latestVersion, err := finder.findLatestVersion(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if desiredVersion.Higher(latestVersion, precision) {
-		return nil, fmt.Errorf("the requested version is higher than the latest version available, latest available is %s", releaseTag(latestVersion))
+		return nil, &VersionNotFoundError{
+			Version: releaseTag(desiredVersion),
+			Reason:  fmt.Sprintf("the requested version is higher than the latest version available, latest available is %s", releaseTag(latestVersion)),
+		}
 	}
 	if desiredVersion.Equal(latestVersion, precision) {
 		return latestVersion, nil
 	}
+	var reason string
 	switch precision {
 	case major:
 		nextMajorVersion := &coreVersion{major: desiredVersion.major + 1, minor: 0, patch: 0}
-		finder.latestSelectedRelease, err = finder.repository.GetPreviousRelease(releaseTag(nextMajorVersion))
+		reason = "release preceding " + releaseTag(nextMajorVersion)
+		finder.latestSelectedRelease, err = finder.repository.GetPreviousRelease(ctx, releaseTag(nextMajorVersion))
 	case minor:
 		nextMinorVersion := &coreVersion{major: desiredVersion.major, minor: desiredVersion.minor + 1, patch: 0}
-		finder.latestSelectedRelease, err = finder.repository.GetPreviousRelease(releaseTag(nextMinorVersion))
+		reason = "release preceding " + releaseTag(nextMinorVersion)
+		finder.latestSelectedRelease, err = finder.repository.GetPreviousRelease(ctx, releaseTag(nextMinorVersion))
 	case patch:
-		finder.latestSelectedRelease, err = finder.repository.GetReleaseByTag(releaseTag(desiredVersion))
+		reason = "release tagged " + releaseTag(desiredVersion)
+		finder.latestSelectedRelease, err = finder.repository.GetReleaseByTag(ctx, releaseTag(desiredVersion))
 	default:
 		panic("Can't fetch version, the comparator key is not known")
 	}
+	finder.remoteCalls++
+	if err != nil {
+		return nil, err
+	}
+	releaseName := finder.latestSelectedRelease.GetName()
+	finder.considered(releaseName, reason)
+	finder.latestSelectedVersion, _, err = parseCoreVersion(releaseName)
+	return finder.latestSelectedVersion, err
+}
+
+// resolveConstraint selects the newest release satisfying constraint, the
+// releases are only listed when the latest one doesn't satisfy it.
+func (finder *finder) resolveConstraint(ctx context.Context, constraint hugoversion.Constraint) (*coreVersion, error) {
+	latestVersion, err := finder.findLatestVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if constraint.Check(hugoversion.Version{Major: latestVersion.major, Minor: latestVersion.minor, Patch: latestVersion.patch}) {
+		return latestVersion, nil
+	}
+	return finder.selectNewest(ctx, constraint.String(), "newest release satisfying "+constraint.String(), func(release Release, version hugoversion.Version) bool {
+		return constraint.Check(version)
+	})
+}
+
+// resolveStable selects the newest release published for at least
+// minReleaseAge, in the minor line preceding the latest one with previousMinor,
+// satisfying constraint.
+func (finder *finder) resolveStable(ctx context.Context, constraint hugoversion.Constraint) (*coreVersion, error) {
+	publishedBefore := now().Add(-finder.minReleaseAge)
+	reason := "newest release published before " + publishedBefore.Format("2006-01-02")
+	spec := LatestStable
+	if len(constraint) > 0 {
+		spec += "," + constraint.String()
+		reason += " satisfying " + constraint.String()
+	}
+	var latestLine *hugoversion.Version
+	if finder.previousMinor {
+		latestVersion, err := finder.findLatestVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		latestLine = &hugoversion.Version{Major: latestVersion.major, Minor: latestVersion.minor}
+		reason += fmt.Sprintf(" preceding the v%d.%d line", latestVersion.major, latestVersion.minor)
+	}
+	return finder.selectNewest(ctx, spec, reason, func(release Release, version hugoversion.Version) bool {
+		publishedAt := release.GetPublishedAt()
+		if (!publishedAt.IsZero() && publishedAt.After(publishedBefore)) || !constraint.Check(version) {
+			return false
+		}
+		return latestLine == nil || version.Less(*latestLine)
+	})
+}
+
+// resolveDate selects the newest release published on or before date
+// satisfying constraint, the releases without publication date being skipped.
+func (finder *finder) resolveDate(ctx context.Context, date time.Time, constraint hugoversion.Constraint) (*coreVersion, error) {
+	publishedBefore := date.AddDate(0, 0, 1)
+	spec := "@" + date.Format(releaseDateLayout)
+	reason := "newest release published on or before " + date.Format(releaseDateLayout)
+	if len(constraint) > 0 {
+		spec += "," + constraint.String()
+		reason += " satisfying " + constraint.String()
+	}
+	return finder.selectNewest(ctx, spec, reason, func(release Release, version hugoversion.Version) bool {
+		publishedAt := release.GetPublishedAt()
+		return !publishedAt.IsZero() && publishedAt.Before(publishedBefore) && constraint.Check(version)
+	})
+}
+
+// selectNewest lists the releases and selects the newest one accepted, the
+// prereleases and drafts being ignored.
+func (finder *finder) selectNewest(ctx context.Context, spec string, reason string, accept func(release Release, version hugoversion.Version) bool) (*coreVersion, error) {
+	releases, err := finder.repository.ListReleases(ctx)
+	finder.remoteCalls++
 	if err != nil {
 		return nil, err
 	}
-	finder.latestSelectedVersion, _, err = parseCoreVersion(finder.latestSelectedRelease.GetName())
+	var selectedRelease Release
+	var selectedVersion hugoversion.Version
+	for _, release := range releases {
+		if release.GetPrerelease() || release.GetDraft() {
+			continue
+		}
+		version, err := hugoversion.Parse(release.GetName())
+		if err != nil || !accept(release, version) {
+			continue
+		}
+		if selectedRelease == nil || selectedVersion.Less(version) {
+			selectedRelease, selectedVersion = release, version
+		}
+	}
+	if selectedRelease == nil {
+		return nil, &VersionNotFoundError{Version: spec, Reason: "no release is the " + reason}
+	}
+	finder.latestSelectedRelease = selectedRelease
+	releaseName := selectedRelease.GetName()
+	finder.considered(releaseName, reason)
+	finder.latestSelectedVersion, _, err = parseCoreVersion(releaseName)
 	return finder.latestSelectedVersion, err
 }
 
+// considered keeps track of the releases looked at while resolving a version.
+func (finder *finder) considered(releaseName string, reason string) {
+	finder.candidates = append(finder.candidates, fmt.Sprintf("%s (%s)", releaseName, reason))
+}
+
+func (finder *finder) assetName(version *Version) (string, error) {
+	return platformAssetName(version, finder.goos, finder.goarch)
+}
+
+func (finder *finder) consideredReleases() []string {
+	return finder.candidates
+}
+
+func (finder *finder) remoteCallCount() int {
+	return finder.remoteCalls
+}
+
 var osToAssetOs = map[string]string{
 	"darwin":    "macOS",
 	"dragonfly": "DragonFlyBSD",
@@ -106,43 +266,76 @@ var archToAssetArch = map[string]string{
 	"arm64": "ARM64",
 }
 
+// goOS and goArch are patched in tests, they must not be inlined.
+//
+//go:noinline
 func goOS() string {
 	return runtime.GOOS
 }
 
+//go:noinline
 func goArch() string {
 	return runtime.GOARCH
 }
 
+// Platform returns the os and architecture names used by the hugo release
+// assets for the current platform.
+func Platform() (assetOs string, assetArch string, err error) {
+	assetOs, osFound := osToAssetOs[goOS()]
+	assetArch, archFound := archToAssetArch[goArch()]
+	if !osFound || !archFound {
+		return assetOs, assetArch, fmt.Errorf("no hugo release is published for %s/%s", goOS(), goArch())
+	}
+	return assetOs, assetArch, nil
+}
+
 func assetName(version *Version) (assetName string, err error) {
+	return platformAssetName(version, goOS(), goArch())
+}
+
+func platformAssetName(version *Version, goos string, goarch string) (assetName string, err error) {
 	var builder strings.Builder
 	builder.WriteString("hugo_")
-	if version.extended {
-		builder.WriteString("extended_")
+	if version.edition() != hugoversion.Standard {
+		builder.WriteString(version.edition().String() + "_")
 	}
-	fmt.Printf("%s", version)
-	fmt.Printf("%d", version.coreVersion.major)
-	fmt.Printf("%d", version.minor)
-	fmt.Fprintf(&builder, "%d.%d", version.major, version.minor)
-	if !(version.major == 0 && version.minor <= 53 && version.patch == 0) {
-		fmt.Fprintf(&builder, ".%d", version.patch)
+	builder.WriteString(assetVersion(version.coreVersion))
+	if version.withDeploy {
+		// the withdeploy edition, published since 0.137, only uses the lowercase
+		// naming of the platforms, macOS having a single universal build
+		if goos == "darwin" {
+			goarch = "universal"
+		}
+		fmt.Fprintf(&builder, "_%s-%s%s", goos, goarch, archiveExtension(goos))
+		return builder.String(), err
 	}
-
-	fmt.Fprintf(&builder, "_%s-%s%s", osToAssetOs[goOS()], archToAssetArch[goArch()], getExtension())
+	fmt.Fprintf(&builder, "_%s-%s%s", osToAssetOs[goos], archToAssetArch[goarch], archiveExtension(goos))
 	return builder.String(), err
 }
 
-func releaseTag(version *coreVersion) string {
-	var builder strings.Builder
-	fmt.Fprintf(&builder, "v%d.%d", version.major, version.minor)
-	if !(version.major == 0 && version.minor <= 53 && version.patch == 0) {
-		fmt.Fprintf(&builder, ".%d", version.patch)
+func checksumsAssetName(version *Version) string {
+	return fmt.Sprintf("hugo_%s_checksums.txt", assetVersion(version.coreVersion))
+}
+
+// assetVersion formats the version like the release assets do, prior to 0.53
+// minor releases have no patch number.
+func assetVersion(version *coreVersion) string {
+	if version.major == 0 && version.minor <= 53 && version.patch == 0 {
+		return fmt.Sprintf("%d.%d", version.major, version.minor)
 	}
-	return builder.String()
+	return fmt.Sprintf("%d.%d.%d", version.major, version.minor, version.patch)
+}
+
+func releaseTag(version *coreVersion) string {
+	return "v" + assetVersion(version)
 }
 
 func getExtension() string {
-	if goOS() == "windows" {
+	return archiveExtension(goOS())
+}
+
+func archiveExtension(goos string) string {
+	if goos == "windows" {
 		return ".zip"
 	}
 	return ".tar.gz"
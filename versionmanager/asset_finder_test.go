@@ -1,9 +1,13 @@
 package versionmanager
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"bou.ke/monkey"
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -20,6 +24,7 @@ func TestAssetName(t *testing.T) {
 	assetNameCoreOldVersion := coreVersion{major: 0, minor: 53, patch: 0}
 	assetNameTestingVersion := Version{coreVersion: &assetNameCoreVersion, extended: false, finder: nil}
 	assetNameTestingExtentedVersion := Version{coreVersion: &assetNameCoreVersion, extended: true, finder: nil}
+	assetNameTestingWithDeployVersion := Version{coreVersion: &assetNameCoreVersion, extended: true, withDeploy: true, finder: nil}
 	assetNameTestingOldVersion := Version{coreVersion: &assetNameCoreOldVersion, extended: false, finder: nil}
 
 	assetNameTestingList := []assetNameTestingItem{
@@ -48,6 +53,9 @@ func TestAssetName(t *testing.T) {
 		assetNameTestingItem{os: "darwin", arch: "amd64", desiredOutput: "hugo_extended_0.73.0_macOS-64bit.tar.gz", version: assetNameTestingExtentedVersion},
 		assetNameTestingItem{os: "linux", arch: "amd64", desiredOutput: "hugo_extended_0.73.0_Linux-64bit.tar.gz", version: assetNameTestingExtentedVersion},
 		assetNameTestingItem{os: "windows", arch: "amd64", desiredOutput: "hugo_extended_0.73.0_Windows-64bit.zip", version: assetNameTestingExtentedVersion},
+		assetNameTestingItem{os: "linux", arch: "amd64", desiredOutput: "hugo_extended_withdeploy_0.73.0_linux-amd64.tar.gz", version: assetNameTestingWithDeployVersion},
+		assetNameTestingItem{os: "darwin", arch: "arm64", desiredOutput: "hugo_extended_withdeploy_0.73.0_darwin-universal.tar.gz", version: assetNameTestingWithDeployVersion},
+		assetNameTestingItem{os: "windows", arch: "amd64", desiredOutput: "hugo_extended_withdeploy_0.73.0_windows-amd64.zip", version: assetNameTestingWithDeployVersion},
 
 		assetNameTestingItem{os: "darwin", arch: "amd64", desiredOutput: "hugo_0.53_macOS-64bit.tar.gz", version: assetNameTestingOldVersion},
 		assetNameTestingItem{os: "dragonfly", arch: "amd64", desiredOutput: "hugo_0.53_DragonFlyBSD-64bit.tar.gz", version: assetNameTestingOldVersion},
@@ -150,6 +158,7 @@ func TestFindLatestVersion(t *testing.T) {
 }
 
 func whenLatestVersionHasNotBeenFetchedYet(t *testing.T) {
+	ctx := context.Background()
 	finder := new(finder)
 	ctrl := gomock.NewController(t)
 
@@ -159,11 +168,11 @@ func whenLatestVersionHasNotBeenFetchedYet(t *testing.T) {
 	repository := NewMockRepositoryClient(ctrl)
 	release := NewMockRelease(ctrl)
 
-	repository.EXPECT().GetLatestRelease().Return(release, nil)
+	repository.EXPECT().GetLatestRelease(gomock.Any()).Return(release, nil)
 	release.EXPECT().GetName().Return("v0.72.3")
 
 	finder.repository = repository
-	finder.findLatestVersion()
+	finder.findLatestVersion(ctx)
 	assert.Equal(t, 0, finder.latestSelectedVersion.major)
 	assert.Equal(t, 72, finder.latestSelectedVersion.minor)
 	assert.Equal(t, 3, finder.latestSelectedVersion.patch)
@@ -182,3 +191,128 @@ func whenLatestVersionHasAlreadyBeenFetched(t *testing.T) {
 	finder.repository = NewMockRepositoryClient(ctrl)
 
 }
+
+func TestResolveConstraint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	repository := NewMockRepositoryClient(ctrl)
+	newRelease := func(name string, prerelease bool) Release {
+		release := NewMockRelease(ctrl)
+		release.EXPECT().GetName().Return(name).AnyTimes()
+		release.EXPECT().GetPrerelease().Return(prerelease).AnyTimes()
+		release.EXPECT().GetDraft().Return(false).AnyTimes()
+		return release
+	}
+	latest := newRelease("v0.80.0", false)
+	repository.EXPECT().GetLatestRelease(gomock.Any()).Return(latest, nil)
+	repository.EXPECT().ListReleases(gomock.Any()).Return([]Release{latest, newRelease("v0.79.0-rc1", true), newRelease("v0.78.2", false), newRelease("v0.72.0", false)}, nil).Times(2)
+
+	finder := newPlatformAssetFinder(repository, "linux", "amd64")
+	constraint, _ := hugoversion.ParseConstraint(">=0.72,<0.80")
+	version, err := finder.resolveConstraint(ctx, constraint)
+	assert.Nil(err)
+	assert.Equal(&coreVersion{major: 0, minor: 78, patch: 2}, version, "the prereleases should be skipped")
+
+	constraint, _ = hugoversion.ParseConstraint(">=0.79")
+	version, err = finder.resolveConstraint(ctx, constraint)
+	assert.Nil(err)
+	assert.Equal(&coreVersion{major: 0, minor: 80, patch: 0}, version, "the latest release should be used without listing")
+
+	constraint, _ = hugoversion.ParseConstraint("<0.50")
+	_, err = finder.resolveConstraint(ctx, constraint)
+	assert.NotNil(err)
+}
+
+func TestResolveStable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+	today := time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC)
+	defer func(original func() time.Time) { now = original }(now)
+	now = func() time.Time { return today }
+
+	repository := NewMockRepositoryClient(ctrl)
+	newRelease := func(name string, age int) Release {
+		release := NewMockRelease(ctrl)
+		release.EXPECT().GetName().Return(name).AnyTimes()
+		release.EXPECT().GetPrerelease().Return(false).AnyTimes()
+		release.EXPECT().GetDraft().Return(false).AnyTimes()
+		release.EXPECT().GetPublishedAt().Return(today.AddDate(0, 0, -age)).AnyTimes()
+		return release
+	}
+	latest := newRelease("v0.121.1", 2)
+	releases := []Release{latest, newRelease("v0.121.0", 10), newRelease("v0.120.4", 30), newRelease("v0.120.3", 40)}
+	repository.EXPECT().GetLatestRelease(gomock.Any()).Return(latest, nil)
+	repository.EXPECT().ListReleases(gomock.Any()).Return(releases, nil).Times(3)
+
+	finder := newPlatformAssetFinder(repository, "linux", "amd64").(*finder)
+	finder.minReleaseAge = 7 * 24 * time.Hour
+	version, err := finder.resolveStable(ctx, nil)
+	assert.Nil(err)
+	assert.Equal(&coreVersion{major: 0, minor: 121, patch: 0}, version, "the releases younger than a week should be skipped")
+
+	finder.previousMinor = true
+	version, err = finder.resolveStable(ctx, nil)
+	assert.Nil(err)
+	assert.Equal(&coreVersion{major: 0, minor: 120, patch: 4}, version, "the latest patch of the previous minor line should be selected")
+
+	finder.previousMinor = false
+	selected, err := newVersion(ctx, finder, "latest-stable,<0.120.4-extended")
+	assert.Nil(err)
+	assert.Equal("v0.120.3-extended", selected.String(), "the newest release old enough satisfying the constraint should be selected")
+
+	versionCore, edition, err := extractEdition("latest-stable-extended")
+	assert.Nil(err)
+	assert.Equal(LatestStable, versionCore)
+	assert.Equal(hugoversion.Extended, edition)
+	for _, spec := range []string{"latest-stable,", "latest-stable,0.72", "latest-stable,>=0.72-deluxe"} {
+		_, _, err = extractEdition(spec)
+		assert.True(errors.Is(err, ErrInvalidVersionSpec), spec)
+	}
+}
+
+func TestResolveDate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	repository := NewMockRepositoryClient(ctrl)
+	newRelease := func(name string, publishedAt time.Time) Release {
+		release := NewMockRelease(ctrl)
+		release.EXPECT().GetName().Return(name).AnyTimes()
+		release.EXPECT().GetPrerelease().Return(false).AnyTimes()
+		release.EXPECT().GetDraft().Return(false).AnyTimes()
+		release.EXPECT().GetPublishedAt().Return(publishedAt).AnyTimes()
+		return release
+	}
+	releases := []Release{
+		newRelease("v0.113.0", time.Date(2023, 6, 2, 9, 0, 0, 0, time.UTC)),
+		newRelease("v0.112.7", time.Date(2023, 6, 1, 18, 30, 0, 0, time.UTC)),
+		newRelease("v0.112.6", time.Date(2023, 5, 30, 12, 0, 0, 0, time.UTC)),
+		newRelease("v0.112.5", time.Time{}),
+	}
+	repository.EXPECT().ListReleases(gomock.Any()).Return(releases, nil).Times(4)
+
+	finder := newPlatformAssetFinder(repository, "linux", "amd64")
+	version, err := newVersion(ctx, finder, "@2023-06-01-extended")
+	assert.Nil(err)
+	assert.Equal("v0.112.7-extended", version.String(), "the release published during the day should be selected")
+	version, err = newVersion(ctx, finder, "@2023-06-01,<0.112.7-extended")
+	assert.Nil(err)
+	assert.Equal("v0.112.6-extended", version.String(), "the newest release of the date satisfying the constraint should be selected")
+	_, err = newVersion(ctx, finder, "@2023-06-01,>=0.113.0")
+	assert.True(errors.Is(err, ErrVersionNotFound), "a release published after the date shouldn't be selected")
+
+	_, err = newVersion(ctx, finder, "@2020-01-01")
+	assert.True(errors.Is(err, ErrVersionNotFound))
+
+	for _, spec := range []string{"@2023-13-01", "@2023-06", "@commit", "@2023-06-01-deluxe"} {
+		_, err = newVersion(ctx, finder, spec)
+		assert.True(errors.Is(err, ErrInvalidVersionSpec), spec)
+	}
+}
@@ -0,0 +1,220 @@
+package versionmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BackendConfig configures a repository backend, each backend reads the fields it needs.
+type BackendConfig struct {
+	// Type is the name the backend has been registered with.
+	Type string
+	// Owner and Repository locate the repository on GitHub, gohugoio/hugo by default.
+	Owner      string
+	Repository string
+	// URL is the root of an http mirror.
+	URL string
+	// Directory is the root of a local directory of releases.
+	Directory string
+	// Location is the text following the type in a list of backends, such as
+	// the url of http-mirror=<url>, read by the backends added with RegisterBackend.
+	Location string
+	// Token authenticates the requests of the github backend.
+	Token      string
+	HTTPClient *http.Client
+}
+
+// BackendFactory builds the client of a backend from its configuration.
+type BackendFactory func(config BackendConfig) (RepositoryClient, error)
+
+var (
+	backendsMutex sync.RWMutex
+	backends      = map[string]BackendFactory{}
+)
+
+func init() {
+	RegisterBackend("github", newGithubBackend)
+	RegisterBackend("http-mirror", newHTTPMirrorBackend)
+	RegisterBackend("file", newFileBackend)
+}
+
+// RegisterBackend makes a backend available under name, it panics if the name is already taken.
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	if factory == nil {
+		panic("the factory of the backend " + name + " is nil")
+	}
+	if _, found := backends[name]; found {
+		panic("a backend is already registered as " + name)
+	}
+	backends[name] = factory
+}
+
+// Backends returns the names of the registered backends.
+func Backends() []string {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBackend builds the client of the backend registered as config.Type.
+func NewBackend(config BackendConfig) (RepositoryClient, error) {
+	backendsMutex.RLock()
+	factory, found := backends[config.Type]
+	backendsMutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown backend %q, the known backends are %s", config.Type, strings.Join(Backends(), ", "))
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: apiTimeout}
+	}
+	return factory(config)
+}
+
+// ParseBackends reads a comma separated list of backends in the form of
+// type[=location], such as "http-mirror=https://mirror.example.com,github".
+// The location is the url of an http mirror, the directory of a file backend,
+// or owner/repository for github, the other backends read it from Location.
+func ParseBackends(text string) ([]BackendConfig, error) {
+	configs := []BackendConfig{}
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		config := BackendConfig{}
+		if index := strings.Index(item, "="); index >= 0 {
+			config.Type, config.Location = item[:index], item[index+1:]
+		} else {
+			config.Type = item
+		}
+		location := config.Location
+		switch config.Type {
+		case "github":
+			if location != "" {
+				splitLocation := strings.Split(location, "/")
+				if len(splitLocation) != 2 {
+					return nil, fmt.Errorf("invalid github repository %q, it must be in form of owner/repository", location)
+				}
+				config.Owner, config.Repository = splitLocation[0], splitLocation[1]
+			}
+		case "http-mirror":
+			config.URL = location
+		case "file":
+			config.Directory = location
+		}
+		configs = append(configs, config)
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("no backend has been given")
+	}
+	return configs, nil
+}
+
+func newGithubBackend(config BackendConfig) (RepositoryClient, error) {
+	if config.Owner == "" && config.Repository == "" {
+		config.Owner, config.Repository = "gohugoio", "hugo"
+	}
+	client := config.HTTPClient
+	if config.Token != "" {
+		client = &http.Client{Transport: &tokenTransport{token: config.Token, base: client.Transport}, Timeout: client.Timeout}
+	}
+	return newGithubRepository(client, config.Owner, config.Repository), nil
+}
+
+// tokenTransport authenticates the requests with a token.
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (transport *tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	base := transport.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// a RoundTripper mustn't modify the request it is given
+	request = request.Clone(request.Context())
+	request.Header.Set("Authorization", "token "+transport.token)
+	return base.RoundTrip(request)
+}
+
+// fallbackRepository asks its backends in order, the next one is used when a
+// backend doesn't know the release or can't be reached.
+type fallbackRepository struct {
+	backends []RepositoryClient
+}
+
+// NewFallbackRepository returns a client trying backends in order of priority.
+func NewFallbackRepository(backends ...RepositoryClient) RepositoryClient {
+	if len(backends) == 1 {
+		return backends[0]
+	}
+	return &fallbackRepository{backends: backends}
+}
+
+func (repo *fallbackRepository) GetLatestRelease(ctx context.Context) (Release, error) {
+	return repo.first(ctx, func(backend RepositoryClient) (Release, error) {
+		return backend.GetLatestRelease(ctx)
+	})
+}
+
+func (repo *fallbackRepository) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
+	return repo.first(ctx, func(backend RepositoryClient) (Release, error) {
+		return backend.GetReleaseByTag(ctx, tag)
+	})
+}
+
+func (repo *fallbackRepository) GetPreviousRelease(ctx context.Context, tag string) (Release, error) {
+	return repo.first(ctx, func(backend RepositoryClient) (Release, error) {
+		return backend.GetPreviousRelease(ctx, tag)
+	})
+}
+
+func (repo *fallbackRepository) ListReleases(ctx context.Context) (releases []Release, err error) {
+	for _, backend := range repo.backends {
+		releases, err = backend.ListReleases(ctx)
+		if err == nil || !canFallBack(ctx, err) {
+			return releases, err
+		}
+	}
+	return nil, err
+}
+
+func (repo *fallbackRepository) first(ctx context.Context, get func(backend RepositoryClient) (Release, error)) (release Release, err error) {
+	for _, backend := range repo.backends {
+		release, err = get(backend)
+		if err == nil || !canFallBack(ctx, err) {
+			return release, err
+		}
+	}
+	return nil, err
+}
+
+// canFallBack tells if an error of a backend may not happen with another one,
+// none can answer once ctx is done.
+func canFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, ErrVersionNotFound) || errors.Is(err, ErrOffline) || errors.Is(err, ErrRateLimited)
+}
+
+// backendsOf returns the backends a repository client is made of.
+func backendsOf(repository RepositoryClient) []RepositoryClient {
+	if fallback, ok := repository.(*fallbackRepository); ok {
+		return fallback.backends
+	}
+	return []RepositoryClient{repository}
+}
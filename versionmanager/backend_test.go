@@ -0,0 +1,227 @@
+package versionmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBackends(t *testing.T) {
+	assert := assert.New(t)
+
+	configs, err := ParseBackends("http-mirror=https://mirror.example.com, file=/srv/hugo,github=owner/hugo,github,s3=bucket/hugo")
+	assert.Nil(err)
+	assert.Equal([]BackendConfig{
+		{Type: "http-mirror", URL: "https://mirror.example.com", Location: "https://mirror.example.com"},
+		{Type: "file", Directory: "/srv/hugo", Location: "/srv/hugo"},
+		{Type: "github", Owner: "owner", Repository: "hugo", Location: "owner/hugo"},
+		{Type: "github"},
+		{Type: "s3", Location: "bucket/hugo"},
+	}, configs, "the location of the other backends should be passed through")
+
+	_, err = ParseBackends("github=hugo")
+	assert.NotNil(err, "the github repository needs an owner")
+	_, err = ParseBackends(" , ")
+	assert.NotNil(err, "at least one backend must be given")
+	_, err = NewBackend(BackendConfig{Type: "ftp"})
+	assert.NotNil(err, "unknown backends should be refused")
+}
+
+func TestFallbackRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	first := NewMockRepositoryClient(ctrl)
+	second := NewMockRepositoryClient(ctrl)
+	release := NewMockRelease(ctrl)
+	first.EXPECT().GetReleaseByTag(gomock.Any(), "v0.72.0").Return(nil, &VersionNotFoundError{Version: "v0.72.0"})
+	second.EXPECT().GetReleaseByTag(gomock.Any(), "v0.72.0").Return(release, nil)
+	first.EXPECT().GetLatestRelease(gomock.Any()).Return(nil, &OfflineError{Reason: "unreachable"})
+	second.EXPECT().GetLatestRelease(gomock.Any()).Return(release, nil)
+	first.EXPECT().GetPreviousRelease(gomock.Any(), "v0.73.0").Return(nil, fmt.Errorf("unexpected answer"))
+
+	repository := NewFallbackRepository(first, second)
+	found, err := repository.GetReleaseByTag(ctx, "v0.72.0")
+	assert.Nil(err)
+	assert.Equal(release, found)
+	found, err = repository.GetLatestRelease(ctx)
+	assert.Nil(err)
+	assert.Equal(release, found)
+	_, err = repository.GetPreviousRelease(ctx, "v0.73.0")
+	assert.NotNil(err, "only the not found and network errors should fall back")
+	assert.Equal(NewFallbackRepository(first), first)
+}
+
+func TestFileBackend(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	releases, err := ioutil.TempDir("", "hugo-releases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(releases)
+	for _, tag := range []string{"v0.71.1", "v0.72.0", "v0.73.0-rc1", "not-a-release"} {
+		os.Mkdir(filepath.Join(releases, tag), 0755)
+	}
+	assetName := "hugo_0.72.0_Linux-64bit.tar.gz"
+	if err := ioutil.WriteFile(filepath.Join(releases, "v0.72.0", assetName), newTestArchive(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(releases, MirrorIndexName), []byte(`[{"tag_name": "v0.71.1", "published_at": "2020-07-01T10:00:00Z"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	repository, err := NewBackend(BackendConfig{Type: "file", Directory: releases})
+	assert.Nil(err)
+	release, err := repository.GetReleaseByTag(ctx, "v0.71.1")
+	assert.Nil(err)
+	assert.Equal(time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC), release.GetPublishedAt(), "the date should be read from the index")
+	release, err = repository.GetReleaseByTag(ctx, "v0.72.0")
+	assert.Nil(err)
+	assert.True(release.GetPublishedAt().IsZero(), "the modification time isn't a publication date")
+
+	manager, err := New(WithInstallDirectory(directory), WithBackends(BackendConfig{Type: "file", Directory: releases}), WithPlatform("linux", "amd64"))
+	assert.Nil(err)
+	version, err := manager.Resolve(ctx, "latest")
+	assert.Nil(err)
+	assert.Equal("v0.72.0", version.String(), "the prereleases shouldn't be the latest")
+	version, err = manager.Resolve(ctx, "0.71")
+	assert.Nil(err)
+	assert.Equal("v0.71.1", version.String())
+
+	version, err = manager.Resolve(ctx, "0.72.0")
+	assert.Nil(err)
+	execPath, err := manager.Install(ctx, version)
+	assert.Nil(err)
+	assert.FileExists(execPath)
+}
+
+func TestHTTPMirrorBackend(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/hugo/releases.json":
+			fmt.Fprint(writer, `[
+				{"name": "v0.72.0", "tag_name": "v0.72.0", "assets": [{"name": "hugo_0.72.0_Linux-64bit.tar.gz"}]},
+				{"tag_name": "v0.71.1", "assets": [{"name": "hugo_0.71.1_Linux-64bit.tar.gz", "browser_download_url": "https://example.com/hugo.tar.gz"}]}
+			]`)
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	defer server.Close()
+
+	repository, err := NewBackend(BackendConfig{Type: "http-mirror", URL: server.URL + "/hugo"})
+	assert.Nil(err)
+	release, err := repository.GetLatestRelease(ctx)
+	assert.Nil(err)
+	asset, err := release.GetAssetByName("hugo_0.72.0_Linux-64bit.tar.gz")
+	assert.Nil(err)
+	assert.Equal(server.URL+"/hugo/v0.72.0/hugo_0.72.0_Linux-64bit.tar.gz", asset.GetDownloadUrl())
+
+	release, err = repository.GetPreviousRelease(ctx, "v0.72.0")
+	assert.Nil(err)
+	assert.Equal("v0.71.1", release.GetName())
+	asset, err = release.GetAssetByName("hugo_0.71.1_Linux-64bit.tar.gz")
+	assert.Nil(err)
+	assert.Equal("https://example.com/hugo.tar.gz", asset.GetDownloadUrl())
+
+	_, err = repository.GetReleaseByTag(ctx, "v0.70.0")
+	assert.True(errors.Is(err, ErrVersionNotFound))
+}
+
+func TestUnavailableMirror(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Error(writer, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	mirror, err := NewBackend(BackendConfig{Type: "http-mirror", URL: server.URL})
+	assert.Nil(err)
+	_, err = mirror.GetLatestRelease(context.Background())
+	assert.True(errors.Is(err, ErrOffline), "an unavailable mirror should be handled like an unreachable one")
+
+	second := NewMockRepositoryClient(ctrl)
+	release := NewMockRelease(ctrl)
+	second.EXPECT().GetLatestRelease(gomock.Any()).Return(release, nil)
+	found, err := NewFallbackRepository(mirror, second).GetLatestRelease(context.Background())
+	assert.Nil(err)
+	assert.Equal(release, found, "the next backend should be used")
+
+	_, _, err = openURL(context.Background(), http.DefaultClient, server.URL+"/hugo.tar.gz")
+	assert.True(errors.Is(err, ErrOffline))
+}
+
+func TestDigestVerification(t *testing.T) {
+	assert := assert.New(t)
+	archive := newTestArchive(t)
+	digest := "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/releases.json" {
+			fmt.Fprintf(writer, `[{"name": "v0.72.0", "assets": [{"name": "hugo_0.72.0_Linux-64bit.tar.gz", "digest": %q}]}]`, digest)
+			return
+		}
+		writer.Write(archive)
+	}))
+	defer server.Close()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	manager, err := New(WithInstallDirectory(directory), WithBackends(BackendConfig{Type: "http-mirror", URL: server.URL}), WithPlatform("linux", "amd64"))
+	assert.Nil(err)
+	ctx := context.Background()
+	version, err := manager.Resolve(ctx, "latest")
+	assert.Nil(err)
+	_, err = manager.Install(ctx, version)
+	assert.True(errors.Is(err, ErrChecksumMismatch), "the digest of the asset should be verified without checksums file")
+}
+
+func TestBackendCancellation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-request.Context().Done()
+			return
+		}
+		fmt.Fprint(writer, `[{"name": "v0.72.0", "assets": []}]`)
+	}))
+	defer server.Close()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+	second := NewMockRepositoryClient(ctrl)
+
+	mirror, err := NewBackend(BackendConfig{Type: "http-mirror", URL: server.URL})
+	assert.Nil(err)
+	manager, err := New(WithInstallDirectory(directory), WithRepositoryClient(NewFallbackRepository(mirror, second)))
+	assert.Nil(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = manager.Resolve(ctx, "latest")
+	assert.True(errors.Is(err, context.DeadlineExceeded), "the request should be abandoned at the deadline, without falling back")
+
+	version, err := manager.Resolve(context.Background(), "latest")
+	assert.Nil(err, "an abandoned loading of the index shouldn't be kept")
+	assert.Equal("v0.72.0", version.String())
+}
@@ -0,0 +1,60 @@
+package versionmanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// ReleasesBetween lists the releases after good up to bad, oldest first, in
+// the edition of bad. The prereleases and drafts are skipped.
+func (manager *Manager) ReleasesBetween(ctx context.Context, good *Version, bad *Version) ([]*Version, error) {
+	if !good.Value().Less(bad.Value()) {
+		return nil, fmt.Errorf("the good version %s must be older than the bad version %s", good, bad)
+	}
+	published, err := manager.publishedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := []hugoversion.Version{}
+	for _, value := range published {
+		if good.Value().Less(value) && !bad.Value().Less(value) {
+			values = append(values, value)
+		}
+	}
+	hugoversion.Sort(values)
+	versions := make([]*Version, 0, len(values))
+	for _, value := range values {
+		version := &Version{
+			coreVersion: &coreVersion{major: value.Major, minor: value.Minor, patch: value.Patch},
+			finder:      manager.newFinder(),
+			observer:    manager.observer,
+		}
+		version.setEdition(bad.edition())
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Bisect returns the first bad version of versions, ordered oldest first,
+// the last one being known to be bad. isGood is called on log2(n) of them.
+func Bisect(versions []*Version, isGood func(version *Version, remaining int) (bool, error)) (*Version, error) {
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no release to bisect")
+	}
+	good, bad := -1, len(versions)-1
+	for bad-good > 1 {
+		middle := (good + bad) / 2
+		isMiddleGood, err := isGood(versions[middle], bad-good-1)
+		if err != nil {
+			return nil, err
+		}
+		if isMiddleGood {
+			good = middle
+		} else {
+			bad = middle
+		}
+	}
+	return versions[bad], nil
+}
@@ -0,0 +1,145 @@
+package versionmanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findChecksum returns the sha256 sum of assetName listed in a checksums
+// file, made of lines in the form of "<sum>  <asset name>".
+func findChecksum(checksums []byte, assetName string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == assetName {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func verifyChecksum(path string, assetName string, checksums []byte) error {
+	expected, found := findChecksum(checksums, assetName)
+	if !found {
+		return &AssetNotFoundError{Asset: assetName, Release: "checksums"}
+	}
+	actual, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return &ChecksumMismatchError{Asset: assetName, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// verifyAssetDigest checks the file downloaded from asset against the sha256
+// digest published by the backend, verified being false when there is none.
+func verifyAssetDigest(asset Asset, path string) (verified bool, err error) {
+	digest := strings.ToLower(asset.GetDigest())
+	if !strings.HasPrefix(digest, "sha256:") {
+		return false, nil
+	}
+	actual, err := fileChecksum(path)
+	if err != nil {
+		return false, err
+	}
+	if expected := strings.TrimPrefix(digest, "sha256:"); actual != expected {
+		return false, &ChecksumMismatchError{Asset: asset.GetName(), Expected: expected, Actual: actual}
+	}
+	return true, nil
+}
+
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	body, _, err := openURL(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// openURL opens an http url, or a file url as served by the file backend.
+// The size is -1 when it is unknown.
+func openURL(ctx context.Context, client *http.Client, rawURL string) (body io.ReadCloser, size int64, err error) {
+	if strings.HasPrefix(rawURL, "file://") {
+		return openFileURL(rawURL)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+	resp, err := client.Do(request)
+	if err != nil {
+		return nil, -1, &OfflineError{Reason: "the download failed", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, -1, statusError(rawURL, resp)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// statusError reports an unexpected answer to a request, a server failing to
+// answer being unavailable like an unreachable one.
+func statusError(rawURL string, resp *http.Response) error {
+	err := fmt.Errorf("download of %s failed: %s", rawURL, resp.Status)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &OfflineError{Reason: "the server is unavailable", Err: err}
+	}
+	return err
+}
+
+func openFileURL(rawURL string) (io.ReadCloser, int64, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, -1, err
+	}
+	path := parsedURL.Path
+	// on windows the path of file:///C:/dir starts with a slash before the volume
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	file, err := os.Open(filepath.FromSlash(path))
+	if err != nil {
+		return nil, -1, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, -1, err
+	}
+	return file, info.Size(), nil
+}
+
+func fileURL(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,147 @@
+package versionmanager
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The files pinning the hugo version for other tools.
+const (
+	ToolVersionsFileName = ".tool-versions"
+	PackageJSONFileName  = "package.json"
+	NetlifyFileName      = "netlify.toml"
+)
+
+// NewToolVersionsSource returns a source reading the hugo line of the closest
+// .tool-versions file of asdf or mise, such as "hugo extended_0.120.4".
+func NewToolVersionsSource(directory string) SpecSource {
+	return &fileSource{fileName: ToolVersionsFileName, directory: directory, parse: parseToolVersions}
+}
+
+// NewPackageJSONSource returns a source reading the hugo-bin configuration of
+// the closest package.json, its version and buildTags.
+func NewPackageJSONSource(directory string) SpecSource {
+	return &fileSource{fileName: PackageJSONFileName, directory: directory, parse: parsePackageJSON}
+}
+
+// NewNetlifySource returns a source reading the HUGO_VERSION variable of the
+// build environment declared in the closest netlify.toml.
+func NewNetlifySource(directory string) SpecSource {
+	return &fileSource{fileName: NetlifyFileName, directory: directory, parse: parseNetlifyConfig}
+}
+
+func parseToolVersions(content []byte) (string, string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || (fields[0] != "hugo" && fields[0] != "hugo-extended") {
+			continue
+		}
+		// the first version is the one used, the next ones are fallbacks
+		version := fields[1]
+		if strings.Contains(version, ":") || version == "system" {
+			// the hugo of the system or built from a ref or a path, no release is declared
+			return "", "", nil
+		}
+		edition := ""
+		for _, prefix := range []string{"extended_withdeploy_", "extended_"} {
+			if strings.HasPrefix(version, prefix) {
+				version, edition = strings.TrimPrefix(version, prefix), strings.TrimSuffix(prefix, "_")
+				break
+			}
+		}
+		if fields[0] == "hugo-extended" && edition == "" {
+			edition = "extended"
+		}
+		return withEdition(version, edition), strings.Join(fields[:2], " "), nil
+	}
+	return "", "", scanner.Err()
+}
+
+func parsePackageJSON(content []byte) (string, string, error) {
+	var packageJSON struct {
+		HugoBin *struct {
+			Version   string `json:"version"`
+			BuildTags string `json:"buildTags"`
+		} `json:"hugo-bin"`
+	}
+	if err := json.Unmarshal(content, &packageJSON); err != nil {
+		return "", "", err
+	}
+	if packageJSON.HugoBin == nil || packageJSON.HugoBin.Version == "" {
+		return "", "", nil
+	}
+	edition := ""
+	buildTags := strings.Split(packageJSON.HugoBin.BuildTags, ",")
+	for _, buildTag := range buildTags {
+		switch strings.TrimSpace(buildTag) {
+		case "extended":
+			if edition == "" {
+				edition = "extended"
+			}
+		case "withdeploy":
+			edition = "extended_withdeploy"
+		}
+	}
+	declaration := "hugo-bin version " + packageJSON.HugoBin.Version
+	if packageJSON.HugoBin.BuildTags != "" {
+		declaration += ", buildTags " + packageJSON.HugoBin.BuildTags
+	}
+	return withEdition(packageJSON.HugoBin.Version, edition), declaration, nil
+}
+
+// parseNetlifyConfig reads HUGO_VERSION from [context.production.environment],
+// overriding [build.environment] like Netlify does. Netlify installs the extended edition.
+func parseNetlifyConfig(content []byte) (string, string, error) {
+	var netlify struct {
+		Build struct {
+			Environment map[string]interface{} `toml:"environment"`
+		} `toml:"build"`
+		Context struct {
+			Production struct {
+				Environment map[string]interface{} `toml:"environment"`
+			} `toml:"production"`
+		} `toml:"context"`
+	}
+	if _, err := toml.Decode(string(content), &netlify); err != nil {
+		return "", "", err
+	}
+	for _, environment := range []struct {
+		section   string
+		variables map[string]interface{}
+	}{
+		{"context.production.environment", netlify.Context.Production.Environment},
+		{"build.environment", netlify.Build.Environment},
+	} {
+		// the other variables, such as NODE_VERSION = 18, may be of any type
+		value, found := environment.variables["HUGO_VERSION"]
+		if !found {
+			continue
+		}
+		version, ok := value.(string)
+		if !ok {
+			return "", "", fmt.Errorf("HUGO_VERSION of [%s] must be a string, such as \"0.120.4\"", environment.section)
+		}
+		if version = strings.TrimSpace(version); version != "" {
+			return withEdition(version, "extended"), fmt.Sprintf("[%s] HUGO_VERSION = %q", environment.section, version), nil
+		}
+	}
+	return "", "", nil
+}
+
+func withEdition(version string, edition string) string {
+	version = strings.TrimSpace(version)
+	if edition == "" {
+		return version
+	}
+	return version + "-" + edition
+}
@@ -0,0 +1,42 @@
+package versionmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompatibilityFormats(t *testing.T) {
+	assert := assert.New(t)
+	for _, item := range []struct {
+		parse       func(content []byte) (string, string, error)
+		content     string
+		spec        string
+		declaration string
+	}{
+		{parseToolVersions, "nodejs 20.1.0\nhugo extended_0.120.4 0.119.0 # pinned\n", "0.120.4-extended", "hugo extended_0.120.4"},
+		{parseToolVersions, "hugo-extended 0.120.4\n", "0.120.4-extended", "hugo-extended 0.120.4"},
+		{parseToolVersions, "hugo extended_withdeploy_0.137.0\n", "0.137.0-extended_withdeploy", "hugo extended_withdeploy_0.137.0"},
+		{parseToolVersions, "nodejs 20.1.0\n", "", ""},
+		{parseToolVersions, "hugo system\n", "", ""},
+		{parseToolVersions, "hugo ref:master 0.120.4\n", "", ""},
+		{parsePackageJSON, `{"name": "site", "hugo-bin": {"version": "0.120.4", "buildTags": "extended"}}`, "0.120.4-extended", "hugo-bin version 0.120.4, buildTags extended"},
+		{parsePackageJSON, `{"hugo-bin": {"version": "latest"}}`, "latest", "hugo-bin version latest"},
+		{parsePackageJSON, `{"hugo-bin": {"buildTags": "extended"}}`, "", ""},
+		{parseNetlifyConfig, "[build]\ncommand = \"hugo\"\n[build.environment]\nHUGO_VERSION = \"0.120.4\"\n", "0.120.4-extended", `[build.environment] HUGO_VERSION = "0.120.4"`},
+		{parseNetlifyConfig, "[context.production.environment]\nHUGO_VERSION = \"0.110.0\"\n", "0.110.0-extended", `[context.production.environment] HUGO_VERSION = "0.110.0"`},
+		{parseNetlifyConfig, "[build.environment]\nHUGO_VERSION = \"0.120.4\"\n[context.production.environment]\nHUGO_VERSION = \"0.110.0\"\n", "0.110.0-extended", `[context.production.environment] HUGO_VERSION = "0.110.0"`},
+		{parseNetlifyConfig, "[build.environment]\nNODE_VERSION = 18\nHUGO_VERSION = \"0.120.4\"\n", "0.120.4-extended", `[build.environment] HUGO_VERSION = "0.120.4"`},
+		{parseNetlifyConfig, "[build.environment]\nNODE_VERSION = 18\n", "", ""},
+	} {
+		spec, declaration, err := item.parse([]byte(item.content))
+		assert.Nil(err, item.content)
+		assert.Equal(item.spec, spec, item.content)
+		assert.Equal(item.declaration, declaration, item.content)
+	}
+
+	_, _, err := parseNetlifyConfig([]byte("[build.environment]\nHUGO_VERSION = 0.12\n"))
+	assert.NotNil(err, "a version which isn't a string may have lost its zeros")
+	_, _, err = parsePackageJSON([]byte("{"))
+	assert.NotNil(err)
+}
@@ -0,0 +1,445 @@
+package versionmanager
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFileName is the name of the user configuration file of the wrapper, in its configuration directory.
+const ConfigFileName = "config.yaml"
+
+// ProjectConfigFileName is the name of the configuration file of a project,
+// its settings override the user ones.
+const ProjectConfigFileName = ".hugo-wrapper.yaml"
+
+// Config is the configuration of the wrapper, the empty settings keeping their default.
+type Config struct {
+	// InstallDir is the directory the versions are installed in.
+	InstallDir string `yaml:"install_dir,omitempty"`
+	// DefaultVersion is the spec used when no other source declares one, set by use --global.
+	DefaultVersion string `yaml:"default_version,omitempty" toml:"default_version,omitempty"`
+	// DefaultEdition is added to the default version when it doesn't give an edition.
+	DefaultEdition string `yaml:"default_edition,omitempty"`
+	// Backends lists the backends in order of priority, in the form of ParseBackends.
+	Backends []string `yaml:"backends,omitempty"`
+	// GithubToken authenticates the requests to the GitHub API, raising its rate limit.
+	GithubToken string      `yaml:"github_token,omitempty"`
+	HTTP        HTTPConfig  `yaml:"http,omitempty"`
+	Cache       CacheConfig `yaml:"cache,omitempty"`
+	// LogLevel is quiet, info or debug.
+	LogLevel string `yaml:"log_level,omitempty"`
+}
+
+type HTTPConfig struct {
+	// Timeout bounds the requests to the repositories, and the wait for the response of a download.
+	Timeout string `yaml:"timeout,omitempty"`
+	Proxy   string `yaml:"proxy,omitempty"`
+	// CAFile holds PEM certificates trusted in addition to the ones of the system.
+	CAFile string `yaml:"ca_file,omitempty"`
+}
+
+type CacheConfig struct {
+	// TTL is the time the cached release notes and release checks are used.
+	TTL string `yaml:"ttl,omitempty"`
+	// KeepVersions is the number of installed versions kept, every version being kept when 0.
+	KeepVersions int `yaml:"keep_versions,omitempty"`
+}
+
+// ConfigKey is a setting of the configuration, as read and written by wrapper-config get and set.
+type ConfigKey struct {
+	Name        string
+	Description string
+	// Project tells if the key can be set in the configuration file of a project.
+	Project bool
+	// Secret keys aren't shown by wrapper-config list.
+	Secret   bool
+	get      func(config *Config) string
+	validate func(value string) error
+	assign   func(config *Config, value string)
+}
+
+var configKeys = []ConfigKey{
+	{
+		Name:        "install_dir",
+		Description: "directory the hugo versions are installed in",
+		get:         func(config *Config) string { return config.InstallDir },
+		assign:      func(config *Config, value string) { config.InstallDir = value },
+	},
+	{
+		Name:        "default_version",
+		Description: "version used when no other source declares one",
+		Project:     true,
+		get:         func(config *Config) string { return config.DefaultVersion },
+		validate: func(value string) error {
+			_, _, err := extractEdition(value)
+			return err
+		},
+		assign: func(config *Config, value string) { config.DefaultVersion = value },
+	},
+	{
+		Name:        "default_edition",
+		Description: "edition of the default version when it doesn't give one: standard, extended or withdeploy",
+		Project:     true,
+		get:         func(config *Config) string { return config.DefaultEdition },
+		validate: func(value string) error {
+			if _, err := hugoversion.ParseEdition(value); err != nil {
+				return fmt.Errorf("invalid edition %q, it must be standard, extended or withdeploy", value)
+			}
+			return nil
+		},
+		assign: func(config *Config, value string) { config.DefaultEdition = value },
+	},
+	{
+		Name:        "backends",
+		Description: "comma separated backends in order of priority, such as http-mirror=<url>,github",
+		// a project selecting the backend would choose the executables run by the wrapper
+		get: func(config *Config) string { return strings.Join(config.Backends, ",") },
+		validate: func(value string) error {
+			_, err := ParseBackends(value)
+			return err
+		},
+		assign: func(config *Config, value string) {
+			config.Backends = nil
+			for _, backend := range strings.Split(value, ",") {
+				if backend = strings.TrimSpace(backend); backend != "" {
+					config.Backends = append(config.Backends, backend)
+				}
+			}
+		},
+	},
+	{
+		Name:        "github_token",
+		Description: "token authenticating the requests to the GitHub API",
+		Secret:      true,
+		get:         func(config *Config) string { return config.GithubToken },
+		assign:      func(config *Config, value string) { config.GithubToken = value },
+	},
+	{
+		Name:        "http.timeout",
+		Description: "timeout of the requests, such as 30s or 2m",
+		Project:     true,
+		get:         func(config *Config) string { return config.HTTP.Timeout },
+		validate:    validateDuration,
+		assign:      func(config *Config, value string) { config.HTTP.Timeout = value },
+	},
+	{
+		Name:        "http.proxy",
+		Description: "url of the proxy the requests go through",
+		get:         func(config *Config) string { return config.HTTP.Proxy },
+		validate: func(value string) error {
+			if proxy, err := url.Parse(value); err != nil || proxy.Scheme == "" || proxy.Host == "" {
+				return fmt.Errorf("invalid proxy %q, it must be an url such as http://proxy.example.com:3128", value)
+			}
+			return nil
+		},
+		assign: func(config *Config, value string) { config.HTTP.Proxy = value },
+	},
+	{
+		Name:        "http.ca_file",
+		Description: "file of PEM certificates trusted in addition to the ones of the system",
+		get:         func(config *Config) string { return config.HTTP.CAFile },
+		assign:      func(config *Config, value string) { config.HTTP.CAFile = value },
+	},
+	{
+		Name:        "cache.ttl",
+		Description: "time the cached release notes and release checks are used, such as 24h",
+		Project:     true,
+		get:         func(config *Config) string { return config.Cache.TTL },
+		validate:    validateDuration,
+		assign:      func(config *Config, value string) { config.Cache.TTL = value },
+	},
+	{
+		Name:        "cache.keep_versions",
+		Description: "number of installed versions kept, the highest ones, 0 keeping them all",
+		get: func(config *Config) string {
+			if config.Cache.KeepVersions == 0 {
+				return ""
+			}
+			return strconv.Itoa(config.Cache.KeepVersions)
+		},
+		validate: func(value string) error {
+			if keep, err := strconv.Atoi(value); err != nil || keep < 0 {
+				return fmt.Errorf("invalid number of versions %q, it must be a positive integer", value)
+			}
+			return nil
+		},
+		assign: func(config *Config, value string) { config.Cache.KeepVersions, _ = strconv.Atoi(value) },
+	},
+	{
+		Name:        "log_level",
+		Description: "messages written by the wrapper: quiet, info or debug",
+		Project:     true,
+		get:         func(config *Config) string { return config.LogLevel },
+		validate: func(value string) error {
+			if value != "quiet" && value != "info" && value != "debug" {
+				return fmt.Errorf("invalid log level %q, it must be quiet, info or debug", value)
+			}
+			return nil
+		},
+		assign: func(config *Config, value string) { config.LogLevel = value },
+	},
+}
+
+func validateDuration(value string) error {
+	if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+		return fmt.Errorf("invalid duration %q, it must be such as 30s, 10m or 24h", value)
+	}
+	return nil
+}
+
+// ConfigKeys returns the keys of the configuration.
+func ConfigKeys() []ConfigKey {
+	return append([]ConfigKey{}, configKeys...)
+}
+
+// LookupConfigKey returns the key named name.
+func LookupConfigKey(name string) (ConfigKey, error) {
+	names := make([]string, 0, len(configKeys))
+	for _, key := range configKeys {
+		if key.Name == name {
+			return key, nil
+		}
+		names = append(names, key.Name)
+	}
+	return ConfigKey{}, fmt.Errorf("unknown configuration key %q, the keys are %s", name, strings.Join(names, ", "))
+}
+
+// Get returns the value of the key in config, empty when it isn't set.
+func (key ConfigKey) Get(config *Config) string {
+	return key.get(config)
+}
+
+// Set validates value and sets it in config, an empty value unsetting the key.
+func (key ConfigKey) Set(config *Config, value string) error {
+	value = strings.TrimSpace(value)
+	if value != "" && key.validate != nil {
+		if err := key.validate(value); err != nil {
+			return fmt.Errorf("%s: %w", key.Name, err)
+		}
+	}
+	key.assign(config, value)
+	return nil
+}
+
+// Validate checks every setting of config, project restricting them to the
+// keys allowed in the configuration file of a project.
+func (config *Config) Validate(project bool) error {
+	for _, key := range configKeys {
+		value := key.get(config)
+		if value == "" {
+			continue
+		}
+		if project && !key.Project {
+			return fmt.Errorf("%s can only be set in the user configuration", key.Name)
+		}
+		if err := key.Set(new(Config), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge returns config overridden by the settings of other.
+func (config *Config) Merge(other *Config) *Config {
+	merged := new(Config)
+	for _, key := range configKeys {
+		value := key.get(other)
+		if value == "" {
+			value = key.get(config)
+		}
+		key.Set(merged, value)
+	}
+	return merged
+}
+
+// ReadConfig reads the configuration file at path, a missing file being an
+// empty configuration. The files ending with .toml are read in the format of
+// the former configuration file, holding the default version only.
+func ReadConfig(path string) (*Config, error) {
+	config := new(Config)
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(path) == ".toml" {
+		_, err = toml.Decode(string(content), config)
+	} else {
+		err = yaml.UnmarshalStrict(content, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	if err = config.Validate(filepath.Base(path) == ProjectConfigFileName); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Write saves the configuration at path, creating its directory if needed.
+func (config *Config) Write(path string) error {
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+// FindProjectConfig returns the path of the closest project configuration
+// file, or an empty path if there is none between directory and the root of the filesystem.
+func FindProjectConfig(directory string) (string, error) {
+	return findUpward(directory, ProjectConfigFileName)
+}
+
+// Options returns the options of a manager applying the configuration, but
+// the install directory, the messages being written to output.
+func (config *Config) Options(output io.Writer) ([]Option, error) {
+	options := []Option{}
+	switch config.LogLevel {
+	case "quiet":
+		options = append(options, WithLogger(NewWriterLogger(ioutil.Discard, false)))
+	case "debug":
+		options = append(options, WithLogger(NewWriterLogger(output, true)))
+	default:
+		options = append(options, WithOutput(output))
+	}
+
+	apiClient, downloadClient, err := config.httpClients()
+	if err != nil {
+		return nil, err
+	}
+	if downloadClient != nil {
+		options = append(options, WithHTTPClient(downloadClient))
+	}
+	backends := []BackendConfig{{Type: "github"}}
+	if len(config.Backends) > 0 {
+		if backends, err = ParseBackends(strings.Join(config.Backends, ",")); err != nil {
+			return nil, err
+		}
+	}
+	for i := range backends {
+		backends[i].HTTPClient = apiClient
+		if backends[i].Type == "github" {
+			backends[i].Token = config.GithubToken
+		}
+	}
+	options = append(options, WithBackends(backends...))
+
+	if config.Cache.TTL != "" {
+		ttl, err := time.ParseDuration(config.Cache.TTL)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithCacheTTL(ttl))
+	}
+	if config.Cache.KeepVersions > 0 {
+		options = append(options, WithRetention(config.Cache.KeepVersions))
+	}
+	return options, nil
+}
+
+// WrapperClients returns the client of the repository the wrapper is released
+// from and the client of its downloads, applying the http settings and the github token.
+func (config *Config) WrapperClients() (repository RepositoryClient, downloadClient *http.Client, err error) {
+	apiClient, downloadClient, err := config.httpClients()
+	if err != nil {
+		return nil, nil, err
+	}
+	repository, err = NewBackend(BackendConfig{
+		Type:       "github",
+		Owner:      WrapperOwner,
+		Repository: WrapperRepository,
+		Token:      config.GithubToken,
+		HTTPClient: apiClient,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return repository, downloadClient, nil
+}
+
+// httpClients returns the clients of the repository APIs and of the downloads,
+// nil when the http settings aren't set.
+func (config *Config) httpClients() (apiClient *http.Client, downloadClient *http.Client, err error) {
+	if config.HTTP == (HTTPConfig{}) {
+		return nil, nil, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	timeout := apiTimeout
+	if config.HTTP.Timeout != "" {
+		if timeout, err = time.ParseDuration(config.HTTP.Timeout); err != nil {
+			return nil, nil, err
+		}
+		transport.ResponseHeaderTimeout = timeout
+	}
+	if config.HTTP.Proxy != "" {
+		proxy, err := url.Parse(config.HTTP.Proxy)
+		if err != nil {
+			return nil, nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if config.HTTP.CAFile != "" {
+		certificates, err := ioutil.ReadFile(config.HTTP.CAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("http.ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(certificates) {
+			return nil, nil, fmt.Errorf("http.ca_file: no PEM certificate found in %s", config.HTTP.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, &http.Client{Transport: transport}, nil
+}
+
+type configSource struct {
+	name   string
+	config *Config
+}
+
+// NewConfigSource returns the source of the default version of config, the
+// default edition being added to it when it doesn't give one.
+func NewConfigSource(name string, config *Config) SpecSource {
+	return &configSource{name: name, config: config}
+}
+
+func (source *configSource) Name() string {
+	return source.name
+}
+
+func (source *configSource) Lookup() (string, bool, error) {
+	spec := source.config.DefaultVersion
+	if source.config.DefaultEdition == "" {
+		return spec, spec != "", nil
+	}
+	if spec == "" {
+		spec = "latest"
+	}
+	if core, _, err := extractEdition(spec); err == nil && core == spec {
+		spec += "-" + source.config.DefaultEdition
+	}
+	return spec, true, nil
+}
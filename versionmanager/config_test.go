@@ -0,0 +1,126 @@
+package versionmanager
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigKeys(t *testing.T) {
+	assert := assert.New(t)
+	config := new(Config)
+
+	_, err := LookupConfigKey("install-dir")
+	assert.NotNil(err, "unknown keys should be refused")
+	for name, value := range map[string]string{
+		"default_version":     "0.72-extended",
+		"default_edition":     "extended",
+		"backends":            "http-mirror=https://mirror.example.com, github",
+		"http.timeout":        "10s",
+		"cache.keep_versions": "3",
+		"log_level":           "debug",
+	} {
+		key, err := LookupConfigKey(name)
+		assert.Nil(err)
+		assert.Nil(key.Set(config, value), name)
+	}
+	assert.Equal([]string{"http-mirror=https://mirror.example.com", "github"}, config.Backends)
+	assert.Equal(3, config.Cache.KeepVersions)
+
+	for name, value := range map[string]string{
+		"default_version":     "0.72-unknown",
+		"default_edition":     "deluxe",
+		"backends":            "github=gohugoio",
+		"http.timeout":        "10",
+		"http.proxy":          "proxy",
+		"cache.keep_versions": "-1",
+		"log_level":           "verbose",
+	} {
+		key, _ := LookupConfigKey(name)
+		assert.NotNil(key.Set(config, value), name+" should be validated")
+	}
+	assert.Equal("10s", config.HTTP.Timeout, "an invalid value should not be set")
+
+	key, _ := LookupConfigKey("http.timeout")
+	assert.Nil(key.Set(config, ""))
+	assert.Equal("", config.HTTP.Timeout, "an empty value should unset the key")
+
+	merged := config.Merge(&Config{LogLevel: "quiet"})
+	assert.Equal("quiet", merged.LogLevel)
+	assert.Equal("0.72-extended", merged.DefaultVersion)
+}
+
+func TestReadConfig(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "config")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, ConfigFileName)
+	writeSiteFile(t, path, "default_version: latest\nhttp:\n  timeout: 1m\n  prxy: http://proxy\n")
+	_, err = ReadConfig(path)
+	assert.NotNil(err, "unknown keys should be refused")
+	writeSiteFile(t, path, "cache:\n  ttl: day\n")
+	_, err = ReadConfig(path)
+	assert.NotNil(err, "the values should be validated")
+
+	projectPath := filepath.Join(root, ProjectConfigFileName)
+	writeSiteFile(t, projectPath, "github_token: secret\n")
+	_, err = ReadConfig(projectPath)
+	assert.NotNil(err, "the secrets can't be set in a project")
+	writeSiteFile(t, projectPath, "backends: [http-mirror=https://mirror.example.com]\n")
+	_, err = ReadConfig(projectPath)
+	assert.NotNil(err, "the backends can't be set in a project")
+	writeSiteFile(t, projectPath, "default_edition: extended\n")
+	project, err := ReadConfig(projectPath)
+	assert.Nil(err)
+	found, err := FindProjectConfig(filepath.Join(root, "content", "posts"))
+	assert.Nil(err)
+	assert.Equal(projectPath, found)
+	spec, declared, err := NewConfigSource("project default", project).Lookup()
+	assert.Nil(err)
+	assert.True(declared)
+	assert.Equal("latest-extended", spec, "the default edition should apply to the default version")
+	spec, _, _ = NewConfigSource("default", &Config{DefaultVersion: "@2023-06-01", DefaultEdition: "extended"}).Lookup()
+	assert.Equal("@2023-06-01-extended", spec)
+	spec, _, _ = NewConfigSource("default", &Config{DefaultVersion: "0.72-standard", DefaultEdition: "extended"}).Lookup()
+	assert.Equal("0.72-standard", spec, "the edition given by the version should be kept")
+
+	legacyPath := filepath.Join(root, "config.toml")
+	writeSiteFile(t, legacyPath, "default_version = \"0.120\"\n")
+	legacy, err := ReadConfig(legacyPath)
+	assert.Nil(err)
+	assert.Equal("0.120", legacy.DefaultVersion, "the former configuration file should be read")
+}
+
+func TestWrapperClients(t *testing.T) {
+	assert := assert.New(t)
+	tunnels := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case tunnels <- request.Method + " " + request.Host:
+		default:
+		}
+		writer.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	repository, downloadClient, err := (&Config{HTTP: HTTPConfig{Proxy: proxy.URL}, GithubToken: "secret"}).WrapperClients()
+	assert.Nil(err)
+	assert.NotNil(downloadClient, "the downloads should go through the proxy too")
+	_, err = LatestWrapperRelease(context.Background(), repository, "linux", "amd64")
+	assert.NotNil(err)
+	assert.Equal("CONNECT api.github.com:443", <-tunnels, "the releases should be fetched through the proxy")
+
+	_, downloadClient, err = (&Config{}).WrapperClients()
+	assert.Nil(err)
+	assert.Nil(downloadClient, "the default client should be used without http settings")
+	_, _, err = (&Config{HTTP: HTTPConfig{CAFile: "missing.pem"}}).WrapperClients()
+	assert.NotNil(err)
+}
@@ -0,0 +1,142 @@
+package versionmanager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The errors returned by the package can be matched against these values with errors.Is.
+var (
+	ErrVersionNotFound    = errors.New("version not found")
+	ErrAssetNotFound      = errors.New("asset not found")
+	ErrInvalidVersionSpec = errors.New("invalid version spec")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrChecksumMismatch   = errors.New("checksum mismatch")
+	ErrOffline            = errors.New("offline")
+)
+
+const versionSpecForm = "the version must be in form of latest[-edition], latest-stable[-edition], @yyyy-mm-dd[-edition], @commit[-edition], [v]int[.int[.int]][-edition] or a constraint such as >=0.72,<0.80[-edition], the edition being extended or withdeploy"
+
+type InvalidVersionSpecError struct {
+	Spec string
+}
+
+func (err *InvalidVersionSpecError) Error() string {
+	return fmt.Sprintf("invalid version %q, %s", err.Spec, versionSpecForm)
+}
+
+func (err *InvalidVersionSpecError) Is(target error) bool {
+	return target == ErrInvalidVersionSpec
+}
+
+// VersionNotFoundError is returned when no release matches the requested version.
+type VersionNotFoundError struct {
+	Version string
+	Reason  string
+}
+
+func (err *VersionNotFoundError) Error() string {
+	return fmt.Sprintf("version %s not found, %s", err.Version, err.Reason)
+}
+
+func (err *VersionNotFoundError) Is(target error) bool {
+	return target == ErrVersionNotFound
+}
+
+type AssetNotFoundError struct {
+	Asset   string
+	Release string
+}
+
+func (err *AssetNotFoundError) Error() string {
+	return fmt.Sprintf("asset %s not found in release %s", err.Asset, err.Release)
+}
+
+func (err *AssetNotFoundError) Is(target error) bool {
+	return target == ErrAssetNotFound
+}
+
+// RateLimitError is returned when the quota of the repository API is exhausted.
+type RateLimitError struct {
+	Reset time.Time
+	Err   error
+}
+
+func (err *RateLimitError) Error() string {
+	if err.Reset.IsZero() {
+		return fmt.Sprintf("the rate limit of the repository api is exceeded: %s", err.Err)
+	}
+	return fmt.Sprintf("the rate limit of the repository api is exceeded until %s: %s", err.Reset.Format(time.RFC3339), err.Err)
+}
+
+func (err *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+func (err *RateLimitError) Unwrap() error {
+	return err.Err
+}
+
+type ChecksumMismatchError struct {
+	Asset    string
+	Expected string
+	Actual   string
+}
+
+func (err *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s, expected %s got %s", err.Asset, err.Expected, err.Actual)
+}
+
+func (err *ChecksumMismatchError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// UnsatisfiableError is returned when no version satisfies the requirements
+// of a site, Err being the error of the resolution if there has been one.
+type UnsatisfiableError struct {
+	Requirements []Requirement
+	Err          error
+}
+
+func (err *UnsatisfiableError) Error() string {
+	lines := []string{"no hugo version satisfies the requirements of the site:"}
+	for _, requirement := range err.Requirements {
+		lines = append(lines, "  "+requirement.String())
+	}
+	if err.Err != nil {
+		lines = append(lines, err.Err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (err *UnsatisfiableError) Is(target error) bool {
+	return target == ErrVersionNotFound
+}
+
+func (err *UnsatisfiableError) Unwrap() error {
+	return err.Err
+}
+
+// OfflineError is returned when the network is needed but can't be used,
+// either because it is unreachable or because of the cache policy.
+type OfflineError struct {
+	Reason string
+	Err    error
+}
+
+func (err *OfflineError) Error() string {
+	if err.Err == nil {
+		return err.Reason
+	}
+	return fmt.Sprintf("%s: %s", err.Reason, err.Err)
+}
+
+func (err *OfflineError) Is(target error) bool {
+	return target == ErrOffline
+}
+
+func (err *OfflineError) Unwrap() error {
+	return err.Err
+}
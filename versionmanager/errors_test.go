@@ -0,0 +1,61 @@
+package versionmanager
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	github "github.com/google/go-github/v31/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidVersionSpec(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	for _, spec := range []string{"", "1.2.3.4", "v0.x", "0.72-beta", "0.72-extended-extended", "-1"} {
+		_, err := newVersion(ctx, nil, spec)
+		assert.True(errors.Is(err, ErrInvalidVersionSpec), "%q should be an invalid spec", spec)
+	}
+}
+
+func TestGithubError(t *testing.T) {
+	assert := assert.New(t)
+	reset := time.Now().Add(time.Hour)
+
+	err := githubError(&github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: reset}}, Response: &http.Response{Request: &http.Request{}}}, "v0.72.0")
+	assert.True(errors.Is(err, ErrRateLimited))
+	var rateLimitErr *RateLimitError
+	assert.True(errors.As(err, &rateLimitErr))
+	assert.Equal(reset, rateLimitErr.Reset)
+
+	err = githubError(&github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}, "v0.72.0")
+	assert.True(errors.Is(err, ErrVersionNotFound))
+
+	unknownErr := errors.New("unknown")
+	assert.Equal(unknownErr, githubError(unknownErr, "v0.72.0"), "unknown errors should be passed as they are")
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	assert := assert.New(t)
+	asset, err := ioutil.TempFile("", "asset")
+	assert.Nil(err)
+	defer os.Remove(asset.Name())
+	asset.WriteString("hugo")
+	asset.Close()
+
+	checksums := []byte("c7511b5a  hugo_0.72.0_Linux-ARM.tar.gz\n" +
+		"b9c1da8c1e4a1a5d0e6ff8a2b1dd7c69a7bee0a8c37e3ee8a3ed5e4d6b7c5e0c  hugo_0.72.0_Linux-64bit.tar.gz\n")
+	err = verifyChecksum(asset.Name(), "hugo_0.72.0_Linux-64bit.tar.gz", checksums)
+	assert.True(errors.Is(err, ErrChecksumMismatch))
+
+	sum, _ := fileChecksum(asset.Name())
+	checksums = append(checksums, []byte(sum+"  hugo_0.72.0_macOS-64bit.tar.gz\n")...)
+	assert.Nil(verifyChecksum(asset.Name(), "hugo_0.72.0_macOS-64bit.tar.gz", checksums))
+
+	err = verifyChecksum(asset.Name(), "hugo_0.72.0_Windows-64bit.zip", checksums)
+	assert.True(errors.Is(err, ErrAssetNotFound))
+}
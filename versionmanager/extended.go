@@ -0,0 +1,113 @@
+package versionmanager
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// extendedFeatures are the template usages only supported by the extended edition.
+var extendedFeatures = []struct {
+	pattern     *regexp.Regexp
+	description string
+}{
+	{regexp.MustCompile(`\b(resources\.ToCSS|css\.Sass|toCSS)\b`), "transpiles Sass"},
+	{regexp.MustCompile(`\.(Resize|Fit|Fill|Crop|Process)\s+"[^"]*\bwebp\b`), "encodes images to WebP"},
+}
+
+// maxScannedSize bounds the size of the templates read while detecting the extended features.
+const maxScannedSize = 1024 * 1024
+
+var errDetected = errors.New("detected")
+
+// DetectExtended tells if the site in sourceDirectory, or its themes, needs
+// the extended edition: Sass files in the assets, or templates transpiling
+// Sass or encoding WebP images. The reason names the first usage found.
+func DetectExtended(sourceDirectory string) (reason string, required bool, err error) {
+	directories := []string{sourceDirectory}
+	themes, _ := filepath.Glob(filepath.Join(sourceDirectory, "themes", "*"))
+	directories = append(directories, themes...)
+	for _, directory := range directories {
+		reason, err = detectExtendedAssets(sourceDirectory, filepath.Join(directory, "assets"))
+		if reason == "" && err == nil {
+			reason, err = detectExtendedTemplates(sourceDirectory, filepath.Join(directory, "layouts"))
+		}
+		if err != nil || reason != "" {
+			return reason, reason != "", err
+		}
+	}
+	return "", false, nil
+}
+
+func detectExtendedAssets(sourceDirectory string, assetsDirectory string) (reason string, err error) {
+	err = walkExisting(assetsDirectory, func(path string, info os.FileInfo) error {
+		extension := strings.ToLower(filepath.Ext(path))
+		if extension == ".scss" || extension == ".sass" {
+			reason = relativePath(sourceDirectory, path) + " is a Sass file"
+			return errDetected
+		}
+		return nil
+	})
+	return reason, err
+}
+
+func detectExtendedTemplates(sourceDirectory string, layoutsDirectory string) (reason string, err error) {
+	err = walkExisting(layoutsDirectory, func(path string, info os.FileInfo) error {
+		if info.Size() > maxScannedSize {
+			return nil
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, feature := range extendedFeatures {
+			if feature.pattern.Match(content) {
+				reason = relativePath(sourceDirectory, path) + " " + feature.description
+				return errDetected
+			}
+		}
+		return nil
+	})
+	return reason, err
+}
+
+// walkExisting calls visit for the files of directory, if it exists.
+func walkExisting(directory string, visit func(path string, info os.FileInfo) error) error {
+	if _, err := os.Stat(directory); err != nil {
+		return nil
+	}
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return visit(path, info)
+	})
+	if err == errDetected {
+		return nil
+	}
+	return err
+}
+
+func relativePath(base string, path string) string {
+	if relative, err := filepath.Rel(base, path); err == nil {
+		return filepath.ToSlash(relative)
+	}
+	return path
+}
+
+// RequireExtended selects the extended edition for a spec without edition, a
+// spec ending with -standard keeps the standard edition.
+func (resolution *SpecResolution) RequireExtended(reason string) bool {
+	desiredVersion, edition, err := extractEdition(resolution.Spec)
+	if err != nil || edition != hugoversion.Standard || strings.HasSuffix(resolution.Spec, "-standard") {
+		return false
+	}
+	resolution.Spec = desiredVersion + "-extended"
+	resolution.ExtendedReason = reason
+	return true
+}
@@ -0,0 +1,44 @@
+package versionmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectExtended(t *testing.T) {
+	assert := assert.New(t)
+	site, err := ioutil.TempDir("", "hugo-site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(site)
+
+	writeSiteFile(t, filepath.Join(site, "assets", "main.css"), "body {}")
+	writeSiteFile(t, filepath.Join(site, "layouts", "_default", "baseof.html"), `{{ $style := resources.Get "main.css" | minify }}`)
+	_, required, err := DetectExtended(site)
+	assert.Nil(err)
+	assert.False(required)
+
+	writeSiteFile(t, filepath.Join(site, "themes", "blog", "layouts", "partials", "image.html"), `{{ $image := $image.Resize "600x webp q75" }}`)
+	reason, required, err := DetectExtended(site)
+	assert.Nil(err)
+	assert.True(required, "the templates of the themes should be scanned")
+	assert.Equal("themes/blog/layouts/partials/image.html encodes images to WebP", reason)
+
+	writeSiteFile(t, filepath.Join(site, "assets", "scss", "main.scss"), "$color: red;")
+	reason, _, _ = DetectExtended(site)
+	assert.Equal("assets/scss/main.scss is a Sass file", reason)
+
+	resolution := &SpecResolution{Spec: "0.120"}
+	assert.True(resolution.RequireExtended(reason))
+	assert.Equal("0.120-extended", resolution.Spec)
+	for _, spec := range []string{"0.120-standard", "latest-withdeploy"} {
+		resolution := &SpecResolution{Spec: spec}
+		assert.False(resolution.RequireExtended(reason), "the edition of %s should be kept", spec)
+		assert.Equal(spec, resolution.Spec)
+	}
+}
@@ -0,0 +1,119 @@
+package versionmanager
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// cacheFileNames are the files of the cache directory.
+var cacheFileNames = []string{releaseNotesFileName, noticesFileName}
+
+// MigrateInstallDirectory moves the installed versions of from into to, and
+// its caches into cacheDirectory, but the entries named in skip, the ones
+// already moved and the one holding the destination. from is removed when it
+// is left empty. The entries are copied when from and the destination are on
+// different filesystems, and moved lists the entries moved.
+func MigrateInstallDirectory(from string, to string, cacheDirectory string, skip ...string) (moved []string, err error) {
+	entries, err := ioutil.ReadDir(from)
+	if err != nil {
+		return nil, err
+	}
+	skipped := map[string]bool{}
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	caches := map[string]bool{}
+	for _, name := range cacheFileNames {
+		caches[name] = true
+	}
+	for _, entry := range entries {
+		directory := to
+		if caches[entry.Name()] {
+			directory = cacheDirectory
+		}
+		source, destination := filepath.Join(from, entry.Name()), filepath.Join(directory, entry.Name())
+		if skipped[entry.Name()] || isWithin(directory, source) {
+			continue
+		}
+		if _, err := os.Lstat(destination); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(directory, 0770); err != nil {
+			return moved, err
+		}
+		if err := moveEntry(source, destination); err != nil {
+			return moved, err
+		}
+		moved = append(moved, entry.Name())
+	}
+	// only an empty directory is removed
+	os.Remove(from)
+	return moved, nil
+}
+
+// moveEntry renames source to destination, or copies it when they are on
+// different filesystems. Any other failure, such as a read-only source, is returned.
+func moveEntry(source string, destination string) error {
+	err := os.Rename(source, destination)
+	var linkErr *os.LinkError
+	if err == nil || !errors.As(err, &linkErr) || linkErr.Err != syscall.EXDEV {
+		return err
+	}
+	if err := copyTree(source, destination); err != nil {
+		os.RemoveAll(destination)
+		return err
+	}
+	return os.RemoveAll(source)
+}
+
+// isWithin tells if path is directory or one of its descendants.
+func isWithin(path string, directory string) bool {
+	relativePath, err := filepath.Rel(directory, path)
+	return err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+func copyTree(source string, destination string) error {
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destination, relativePath)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(source string, destination string, mode os.FileMode) error {
+	input, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	output, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(output, input); err != nil {
+		output.Close()
+		return err
+	}
+	return output.Close()
+}
@@ -0,0 +1,56 @@
+package versionmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateInstallDirectory(t *testing.T) {
+	assert := assert.New(t)
+	from := newTestInstallDirectory(t, "v0.72.0", "v0.73.0")
+	defer os.RemoveAll(from)
+	to := newTestInstallDirectory(t, "v0.73.0")
+	defer os.RemoveAll(to)
+	writeSiteFile(t, filepath.Join(from, "v0.73.0", "hugo"), "old")
+	writeSiteFile(t, filepath.Join(from, "notices.json"), "{}")
+	cache := filepath.Join(to, "cache")
+
+	moved, err := MigrateInstallDirectory(from, to, cache, "v0.73.0")
+	assert.Nil(err)
+	assert.Equal([]string{"notices.json", "v0.72.0"}, moved)
+	_, err = os.Stat(filepath.Join(to, "v0.72.0"))
+	assert.Nil(err, "the versions should be moved")
+	_, err = os.Stat(filepath.Join(cache, "notices.json"))
+	assert.Nil(err, "the caches should be moved to the cache directory")
+	_, err = os.Stat(filepath.Join(from, "v0.73.0"))
+	assert.Nil(err, "the skipped entries should be left")
+
+	moved, err = MigrateInstallDirectory(from, to, to)
+	assert.Nil(err)
+	assert.Empty(moved, "the entries already in the destination shouldn't be moved")
+	content, _ := ioutil.ReadFile(filepath.Join(to, "v0.73.0", "hugo"))
+	assert.Equal("", string(content), "the entries already in the destination should be kept")
+	assert.Nil(os.RemoveAll(filepath.Join(from, "v0.73.0")))
+	_, err = MigrateInstallDirectory(from, to, to)
+	assert.Nil(err)
+	_, err = os.Stat(from)
+	assert.True(os.IsNotExist(err), "the emptied directory should be removed")
+
+	nested := filepath.Join(to, "v0.72.0", "versions")
+	_, err = MigrateInstallDirectory(to, nested, nested)
+	assert.Nil(err)
+	_, err = os.Stat(filepath.Join(nested, "v0.73.0"))
+	assert.Nil(err)
+	_, err = os.Stat(filepath.Join(to, "v0.72.0"))
+	assert.Nil(err, "the entry holding the destination should be left")
+
+	copied := filepath.Join(nested, "copy")
+	assert.Nil(copyTree(filepath.Join(nested, "v0.73.0"), copied))
+	info, err := os.Stat(filepath.Join(copied, binaryName(goOS())))
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0755), info.Mode().Perm(), "the mode of the files should be kept")
+}
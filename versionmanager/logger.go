@@ -0,0 +1,49 @@
+package versionmanager
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Logger receives the messages of a Manager, a *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+}
+
+type writerLogger struct {
+	output  io.Writer
+	verbose bool
+}
+
+// NewWriterLogger returns a logger writing a line per message to output,
+// debug messages are written only when verbose is set.
+func NewWriterLogger(output io.Writer, verbose bool) Logger {
+	return &writerLogger{output: output, verbose: verbose}
+}
+
+func (logger *writerLogger) Debug(msg string, args ...interface{}) {
+	if logger.verbose {
+		logger.write(msg, args)
+	}
+}
+
+func (logger *writerLogger) Info(msg string, args ...interface{}) {
+	logger.write(msg, args)
+}
+
+func (logger *writerLogger) write(msg string, args []interface{}) {
+	var builder strings.Builder
+	builder.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&builder, " %v=%v", args[i], args[i+1])
+	}
+	fmt.Fprintln(logger.output, builder.String())
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(msg string, args ...interface{}) {}
+
+func (discardLogger) Info(msg string, args ...interface{}) {}
@@ -9,6 +9,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	github "github.com/google/go-github/v31/github"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRepositoryClient is a mock of RepositoryClient interface
@@ -35,48 +36,63 @@ func (m *MockRepositoryClient) EXPECT() *MockRepositoryClientMockRecorder {
 }
 
 // GetLatestRelease mocks base method
-func (m *MockRepositoryClient) GetLatestRelease() (Release, error) {
+func (m *MockRepositoryClient) GetLatestRelease(ctx context.Context) (Release, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLatestRelease")
+	ret := m.ctrl.Call(m, "GetLatestRelease", ctx)
 	ret0, _ := ret[0].(Release)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetLatestRelease indicates an expected call of GetLatestRelease
-func (mr *MockRepositoryClientMockRecorder) GetLatestRelease() *gomock.Call {
+func (mr *MockRepositoryClientMockRecorder) GetLatestRelease(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestRelease", reflect.TypeOf((*MockRepositoryClient)(nil).GetLatestRelease))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestRelease", reflect.TypeOf((*MockRepositoryClient)(nil).GetLatestRelease), ctx)
 }
 
 // GetReleaseByTag mocks base method
-func (m *MockRepositoryClient) GetReleaseByTag(tag string) (Release, error) {
+func (m *MockRepositoryClient) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetReleaseByTag", tag)
+	ret := m.ctrl.Call(m, "GetReleaseByTag", ctx, tag)
 	ret0, _ := ret[0].(Release)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetReleaseByTag indicates an expected call of GetReleaseByTag
-func (mr *MockRepositoryClientMockRecorder) GetReleaseByTag(tag interface{}) *gomock.Call {
+func (mr *MockRepositoryClientMockRecorder) GetReleaseByTag(ctx, tag interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleaseByTag", reflect.TypeOf((*MockRepositoryClient)(nil).GetReleaseByTag), tag)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleaseByTag", reflect.TypeOf((*MockRepositoryClient)(nil).GetReleaseByTag), ctx, tag)
 }
 
 // GetPreviousRelease mocks base method
-func (m *MockRepositoryClient) GetPreviousRelease(tag string) (Release, error) {
+func (m *MockRepositoryClient) GetPreviousRelease(ctx context.Context, tag string) (Release, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPreviousRelease", tag)
+	ret := m.ctrl.Call(m, "GetPreviousRelease", ctx, tag)
 	ret0, _ := ret[0].(Release)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPreviousRelease indicates an expected call of GetPreviousRelease
-func (mr *MockRepositoryClientMockRecorder) GetPreviousRelease(tag interface{}) *gomock.Call {
+func (mr *MockRepositoryClientMockRecorder) GetPreviousRelease(ctx, tag interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreviousRelease", reflect.TypeOf((*MockRepositoryClient)(nil).GetPreviousRelease), tag)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreviousRelease", reflect.TypeOf((*MockRepositoryClient)(nil).GetPreviousRelease), ctx, tag)
+}
+
+// ListReleases mocks base method
+func (m *MockRepositoryClient) ListReleases(ctx context.Context) ([]Release, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReleases", ctx)
+	ret0, _ := ret[0].([]Release)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReleases indicates an expected call of ListReleases
+func (mr *MockRepositoryClientMockRecorder) ListReleases(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReleases", reflect.TypeOf((*MockRepositoryClient)(nil).ListReleases), ctx)
 }
 
 // MockRelease is a mock of Release interface
@@ -116,6 +132,76 @@ func (mr *MockReleaseMockRecorder) GetName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetName", reflect.TypeOf((*MockRelease)(nil).GetName))
 }
 
+// GetTagName mocks base method
+func (m *MockRelease) GetTagName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetTagName indicates an expected call of GetTagName
+func (mr *MockReleaseMockRecorder) GetTagName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagName", reflect.TypeOf((*MockRelease)(nil).GetTagName))
+}
+
+// GetPublishedAt mocks base method
+func (m *MockRelease) GetPublishedAt() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublishedAt")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// GetPublishedAt indicates an expected call of GetPublishedAt
+func (mr *MockReleaseMockRecorder) GetPublishedAt() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublishedAt", reflect.TypeOf((*MockRelease)(nil).GetPublishedAt))
+}
+
+// GetPrerelease mocks base method
+func (m *MockRelease) GetPrerelease() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrerelease")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetPrerelease indicates an expected call of GetPrerelease
+func (mr *MockReleaseMockRecorder) GetPrerelease() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrerelease", reflect.TypeOf((*MockRelease)(nil).GetPrerelease))
+}
+
+// GetDraft mocks base method
+func (m *MockRelease) GetDraft() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraft")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetDraft indicates an expected call of GetDraft
+func (mr *MockReleaseMockRecorder) GetDraft() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraft", reflect.TypeOf((*MockRelease)(nil).GetDraft))
+}
+
+// GetBody mocks base method
+func (m *MockRelease) GetBody() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBody")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetBody indicates an expected call of GetBody
+func (mr *MockReleaseMockRecorder) GetBody() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBody", reflect.TypeOf((*MockRelease)(nil).GetBody))
+}
+
 // GetAssetByName mocks base method
 func (m *MockRelease) GetAssetByName(name string) (Asset, error) {
 	m.ctrl.T.Helper()
@@ -131,6 +217,20 @@ func (mr *MockReleaseMockRecorder) GetAssetByName(name interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetByName", reflect.TypeOf((*MockRelease)(nil).GetAssetByName), name)
 }
 
+// ListAssets mocks base method
+func (m *MockRelease) ListAssets() []Asset {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAssets")
+	ret0, _ := ret[0].([]Asset)
+	return ret0
+}
+
+// ListAssets indicates an expected call of ListAssets
+func (mr *MockReleaseMockRecorder) ListAssets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssets", reflect.TypeOf((*MockRelease)(nil).ListAssets))
+}
+
 // MockAsset is a mock of Asset interface
 type MockAsset struct {
 	ctrl     *gomock.Controller
@@ -182,6 +282,86 @@ func (mr *MockAssetMockRecorder) GetDownloadUrl() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDownloadUrl", reflect.TypeOf((*MockAsset)(nil).GetDownloadUrl))
 }
 
+// GetSize mocks base method
+func (m *MockAsset) GetSize() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSize")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// GetSize indicates an expected call of GetSize
+func (mr *MockAssetMockRecorder) GetSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSize", reflect.TypeOf((*MockAsset)(nil).GetSize))
+}
+
+// GetContentType mocks base method
+func (m *MockAsset) GetContentType() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContentType")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetContentType indicates an expected call of GetContentType
+func (mr *MockAssetMockRecorder) GetContentType() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContentType", reflect.TypeOf((*MockAsset)(nil).GetContentType))
+}
+
+// GetDigest mocks base method
+func (m *MockAsset) GetDigest() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigest")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetDigest indicates an expected call of GetDigest
+func (mr *MockAssetMockRecorder) GetDigest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigest", reflect.TypeOf((*MockAsset)(nil).GetDigest))
+}
+
+// MockRateLimitedClient is a mock of RateLimitedClient interface
+type MockRateLimitedClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitedClientMockRecorder
+}
+
+// MockRateLimitedClientMockRecorder is the mock recorder for MockRateLimitedClient
+type MockRateLimitedClientMockRecorder struct {
+	mock *MockRateLimitedClient
+}
+
+// NewMockRateLimitedClient creates a new mock instance
+func NewMockRateLimitedClient(ctrl *gomock.Controller) *MockRateLimitedClient {
+	mock := &MockRateLimitedClient{ctrl: ctrl}
+	mock.recorder = &MockRateLimitedClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRateLimitedClient) EXPECT() *MockRateLimitedClientMockRecorder {
+	return m.recorder
+}
+
+// GetRateLimit mocks base method
+func (m *MockRateLimitedClient) GetRateLimit(ctx context.Context) (*RateLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRateLimit", ctx)
+	ret0, _ := ret[0].(*RateLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRateLimit indicates an expected call of GetRateLimit
+func (mr *MockRateLimitedClientMockRecorder) GetRateLimit(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRateLimit", reflect.TypeOf((*MockRateLimitedClient)(nil).GetRateLimit), ctx)
+}
+
 // MockgithubRepositoryInterface is a mock of githubRepositoryInterface interface
 type MockgithubRepositoryInterface struct {
 	ctrl     *gomock.Controller
@@ -275,3 +455,42 @@ func (mr *MockgithubRepositoryServiceInterfaceMockRecorder) ListReleases(ctx, ow
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReleases", reflect.TypeOf((*MockgithubRepositoryServiceInterface)(nil).ListReleases), ctx, owner, repo, opts)
 }
+
+// MockgithubRateLimitServiceInterface is a mock of githubRateLimitServiceInterface interface
+type MockgithubRateLimitServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockgithubRateLimitServiceInterfaceMockRecorder
+}
+
+// MockgithubRateLimitServiceInterfaceMockRecorder is the mock recorder for MockgithubRateLimitServiceInterface
+type MockgithubRateLimitServiceInterfaceMockRecorder struct {
+	mock *MockgithubRateLimitServiceInterface
+}
+
+// NewMockgithubRateLimitServiceInterface creates a new mock instance
+func NewMockgithubRateLimitServiceInterface(ctrl *gomock.Controller) *MockgithubRateLimitServiceInterface {
+	mock := &MockgithubRateLimitServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockgithubRateLimitServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockgithubRateLimitServiceInterface) EXPECT() *MockgithubRateLimitServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// RateLimits mocks base method
+func (m *MockgithubRateLimitServiceInterface) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimits", ctx)
+	ret0, _ := ret[0].(*github.RateLimits)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RateLimits indicates an expected call of RateLimits
+func (mr *MockgithubRateLimitServiceInterfaceMockRecorder) RateLimits(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimits", reflect.TypeOf((*MockgithubRateLimitServiceInterface)(nil).RateLimits), ctx)
+}
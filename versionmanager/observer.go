@@ -0,0 +1,128 @@
+package versionmanager
+
+import (
+	"fmt"
+	"io"
+)
+
+type EventKind int
+
+const (
+	ResolutionStarted = EventKind(iota)
+	ResolutionFinished
+	CacheHit
+	CacheMiss
+	DownloadStarted
+	DownloadProgress
+	DownloadFinished
+	ChecksumVerified
+	ExtractionFinished
+	ExecutionStarted
+)
+
+var eventKindNames = map[EventKind]string{
+	ResolutionStarted:  "resolution started",
+	ResolutionFinished: "resolution finished",
+	CacheHit:           "cache hit",
+	CacheMiss:          "cache miss",
+	DownloadStarted:    "download started",
+	DownloadProgress:   "download progress",
+	DownloadFinished:   "download finished",
+	ChecksumVerified:   "checksum verified",
+	ExtractionFinished: "extraction finished",
+	ExecutionStarted:   "execution started",
+}
+
+func (kind EventKind) String() string {
+	return eventKindNames[kind]
+}
+
+// Event describes a step of the lifecycle of a version, only the fields
+// relevant to its kind are set.
+type Event struct {
+	Kind    EventKind
+	Spec    string
+	Version string
+	Asset   string
+	Path    string
+	Args    []string
+	// Bytes is the amount downloaded so far, Total is -1 when the size is unknown.
+	Bytes int64
+	Total int64
+}
+
+// Observer receives the events of a Manager, it is called synchronously.
+type Observer interface {
+	OnEvent(event Event)
+}
+
+type ObserverFunc func(event Event)
+
+func (observer ObserverFunc) OnEvent(event Event) {
+	observer(event)
+}
+
+type nopObserver struct{}
+
+func (nopObserver) OnEvent(event Event) {}
+
+type cliObserver struct {
+	output io.Writer
+}
+
+// NewCLIObserver returns the observer rendering the events as the command line does.
+func NewCLIObserver(output io.Writer) Observer {
+	return &cliObserver{output: output}
+}
+
+func (observer *cliObserver) OnEvent(event Event) {
+	switch event.Kind {
+	case ResolutionFinished:
+		fmt.Fprintf(observer.output, "selected version: %s\n", event.Version)
+	case CacheHit:
+		fmt.Fprintln(observer.output, "found local installation")
+	case CacheMiss:
+		fmt.Fprintln(observer.output, "local installation not found")
+		fmt.Fprintln(observer.output, "installation started ...")
+	case DownloadStarted:
+		fmt.Fprintf(observer.output, "download of %s started\n", event.Asset)
+	case DownloadProgress:
+		if event.Total > 0 {
+			fmt.Fprintf(observer.output, "\r%.1f/%.1f MB", megabytes(event.Bytes), megabytes(event.Total))
+		} else {
+			fmt.Fprintf(observer.output, "\r%.1f MB", megabytes(event.Bytes))
+		}
+	case DownloadFinished:
+		fmt.Fprintln(observer.output)
+		fmt.Fprintln(observer.output, "download ended")
+	case ChecksumVerified:
+		fmt.Fprintln(observer.output, "checksum verified")
+	case ExtractionFinished:
+		fmt.Fprintln(observer.output, "installation ended")
+	}
+}
+
+func megabytes(bytes int64) float64 {
+	return float64(bytes) / 1024 / 1024
+}
+
+// progressReader reports the bytes read from a download.
+type progressReader struct {
+	io.Reader
+	observer Observer
+	event    Event
+	reported int64
+}
+
+// progressStep bounds the amount of progress events sent during a download.
+const progressStep = 256 * 1024
+
+func (reader *progressReader) Read(buffer []byte) (int, error) {
+	read, err := reader.Reader.Read(buffer)
+	reader.event.Bytes += int64(read)
+	if reader.event.Bytes-reader.reported >= progressStep || (err == io.EOF && reader.event.Bytes != reader.reported) {
+		reader.reported = reader.event.Bytes
+		reader.observer.OnEvent(reader.event)
+	}
+	return read, err
+}
@@ -0,0 +1,84 @@
+package versionmanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestArchive(t *testing.T) []byte {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	content := []byte("#!/bin/sh\necho hugo\n")
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "hugo", Mode: 0755, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	tarWriter.Write(content)
+	tarWriter.Close()
+	gzipWriter.Close()
+	return buffer.Bytes()
+}
+
+func TestInstallEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	archive := newTestArchive(t)
+	sum := sha256.Sum256(archive)
+	assetName := "hugo_0.72.0_Linux-64bit.tar.gz"
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/checksums" {
+			fmt.Fprintf(writer, "%s  %s\n", hex.EncodeToString(sum[:]), assetName)
+			return
+		}
+		writer.Write(archive)
+	}))
+	defer server.Close()
+
+	repository := NewMockRepositoryClient(ctrl)
+	release := NewMockRelease(ctrl)
+	asset := NewMockAsset(ctrl)
+	checksums := NewMockAsset(ctrl)
+	repository.EXPECT().GetLatestRelease(gomock.Any()).Return(release, nil)
+	release.EXPECT().GetName().Return("v0.72.0").AnyTimes()
+	release.EXPECT().GetAssetByName(assetName).Return(asset, nil)
+	release.EXPECT().GetAssetByName("hugo_0.72.0_checksums.txt").Return(checksums, nil)
+	asset.EXPECT().GetDownloadUrl().Return(server.URL + "/asset")
+	checksums.EXPECT().GetDownloadUrl().Return(server.URL + "/checksums")
+
+	kinds := []EventKind{}
+	observer := ObserverFunc(func(event Event) {
+		if event.Kind != DownloadProgress {
+			kinds = append(kinds, event.Kind)
+		}
+	})
+	manager, err := New(WithInstallDirectory(directory), WithRepositoryClient(repository), WithPlatform("linux", "amd64"), WithObserver(observer))
+	assert.Nil(t, err)
+	ctx := context.Background()
+	version, err := manager.Resolve(ctx, "latest")
+	assert.Nil(t, err)
+	_, err = manager.Install(ctx, version)
+	assert.Nil(t, err)
+	_, err = manager.Install(ctx, version)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []EventKind{
+		ResolutionStarted, ResolutionFinished,
+		CacheMiss, DownloadStarted, DownloadFinished, ChecksumVerified, ExtractionFinished,
+		CacheHit,
+	}, kinds)
+}
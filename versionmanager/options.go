@@ -0,0 +1,165 @@
+package versionmanager
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Option configures a Manager.
+type Option func(manager *Manager) error
+
+type CachePolicy int
+
+const (
+	// CachePreferLocal uses the installed versions, and downloads the missing ones.
+	CachePreferLocal = CachePolicy(iota)
+	// CacheRefresh downloads every version again, replacing the installed ones.
+	CacheRefresh
+	// CacheOffline never reaches the network, only the installed versions can be used.
+	CacheOffline
+)
+
+func WithInstallDirectory(directory string) Option {
+	return func(manager *Manager) error {
+		manager.installDirectory = directory
+		return nil
+	}
+}
+
+// WithCacheDirectory keeps the cached release notes and release checks in
+// directory, created if needed, instead of the install directory.
+func WithCacheDirectory(directory string) Option {
+	return func(manager *Manager) error {
+		manager.cacheDir = directory
+		return nil
+	}
+}
+
+// WithRepositoryClient replaces the default client of the gohugoio/hugo GitHub repository,
+// and the backends given by WithBackends.
+func WithRepositoryClient(repository RepositoryClient) Option {
+	return func(manager *Manager) error {
+		manager.repository = repository
+		return nil
+	}
+}
+
+// WithBackends fetches the releases from backends in order of priority, the
+// next backend is used when a release can't be found or the backend can't be reached.
+func WithBackends(configs ...BackendConfig) Option {
+	return func(manager *Manager) error {
+		if len(configs) == 0 {
+			return fmt.Errorf("no backend has been given")
+		}
+		manager.backends = configs
+		return nil
+	}
+}
+
+// WithHTTPClient sets the client used for the downloads, and for the default repository client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(manager *Manager) error {
+		manager.httpClient = client
+		return nil
+	}
+}
+
+func WithLogger(logger Logger) Option {
+	return func(manager *Manager) error {
+		manager.logger = logger
+		return nil
+	}
+}
+
+// WithOutput writes the messages of the manager to output.
+func WithOutput(output io.Writer) Option {
+	return WithLogger(NewWriterLogger(output, false))
+}
+
+// WithPlatform selects the assets of another platform than the current one,
+// goos and goarch are given in the form of runtime.GOOS and runtime.GOARCH.
+func WithPlatform(goos string, goarch string) Option {
+	return func(manager *Manager) error {
+		if _, found := osToAssetOs[goos]; !found {
+			return fmt.Errorf("no hugo release is published for the os %s", goos)
+		}
+		if _, found := archToAssetArch[goarch]; !found {
+			return fmt.Errorf("no hugo release is published for the architecture %s", goarch)
+		}
+		manager.goos = goos
+		manager.goarch = goarch
+		return nil
+	}
+}
+
+// WithObserver sets the observer receiving the lifecycle events of the manager.
+func WithObserver(observer Observer) Option {
+	return func(manager *Manager) error {
+		manager.observer = observer
+		return nil
+	}
+}
+
+// WithMinReleaseAge sets how long a release must have been published to be
+// selected by latest-stable, a week by default.
+func WithMinReleaseAge(age time.Duration) Option {
+	return func(manager *Manager) error {
+		if age < 0 {
+			return fmt.Errorf("the minimum release age can't be negative")
+		}
+		manager.minReleaseAge = age
+		return nil
+	}
+}
+
+// WithPreviousMinor makes latest-stable select the latest patch of the minor
+// line preceding the latest release.
+func WithPreviousMinor(previousMinor bool) Option {
+	return func(manager *Manager) error {
+		manager.previousMinor = previousMinor
+		return nil
+	}
+}
+
+// WithCacheTTL sets the time the cached release notes and release checks are
+// used before being fetched again, DefaultCacheTTL by default.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(manager *Manager) error {
+		if ttl <= 0 {
+			return fmt.Errorf("the cache ttl must be positive")
+		}
+		manager.cacheTTL = ttl
+		return nil
+	}
+}
+
+// WithRetention keeps the keep highest installed versions, the others being
+// removed by ApplyRetention. Every version is kept by default.
+func WithRetention(keep int) Option {
+	return func(manager *Manager) error {
+		if keep < 0 {
+			return fmt.Errorf("the number of versions kept can't be negative")
+		}
+		manager.keepVersions = keep
+		return nil
+	}
+}
+
+func WithCachePolicy(policy CachePolicy) Option {
+	return func(manager *Manager) error {
+		manager.cachePolicy = policy
+		return nil
+	}
+}
+
+// WithIO sets the standard streams of the hugo processes started by Run.
+func WithIO(stdin io.Reader, stdout io.Writer, stderr io.Writer) Option {
+	return func(manager *Manager) error {
+		manager.stdin = stdin
+		manager.stdout = stdout
+		manager.stderr = stderr
+		return nil
+	}
+}
@@ -0,0 +1,121 @@
+package versionmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// noticesFileName records, in the cache directory, when the newer patches of each line have been checked.
+const noticesFileName = "notices.json"
+
+// noticeTimeout is the time given to the check of NewerPatchNotice.
+const noticeTimeout = 3 * time.Second
+
+// Outdated compares a version with the newest releases.
+type Outdated struct {
+	Current string `json:"current"`
+	// LatestPatch is the newest release of the minor line of the version.
+	LatestPatch string `json:"latest_patch"`
+	// LatestMinor is the newest release of the major line of the version.
+	LatestMinor string `json:"latest_minor"`
+	Latest      string `json:"latest"`
+	// MinorsBehind is the number of minor lines released after the one of the version.
+	MinorsBehind int `json:"minors_behind"`
+}
+
+// IsOutdated tells if a newer release exists.
+func (outdated *Outdated) IsOutdated() bool {
+	return outdated.Current != outdated.Latest
+}
+
+// CheckOutdated compares version with the newest published releases.
+func (manager *Manager) CheckOutdated(ctx context.Context, version *Version) (*Outdated, error) {
+	values, err := manager.publishedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := version.Value()
+	current.Edition = hugoversion.Standard
+	outdated := &Outdated{Current: current.String(), LatestPatch: current.String(), LatestMinor: current.String(), Latest: current.String()}
+	newerLines := map[hugoversion.Version]bool{}
+	latest, latestMinor, latestPatch := current, current, current
+	for _, value := range values {
+		if !current.Less(value) {
+			continue
+		}
+		if latest.Less(value) {
+			latest = value
+		}
+		if value.Major == current.Major && latestMinor.Less(value) {
+			latestMinor = value
+		}
+		if value.Major == current.Major && value.Minor == current.Minor && latestPatch.Less(value) {
+			latestPatch = value
+		}
+		if value.Major != current.Major || value.Minor != current.Minor {
+			newerLines[hugoversion.Version{Major: value.Major, Minor: value.Minor}] = true
+		}
+	}
+	outdated.Latest, outdated.LatestMinor, outdated.LatestPatch = latest.String(), latestMinor.String(), latestPatch.String()
+	outdated.MinorsBehind = len(newerLines)
+	return outdated, nil
+}
+
+// NewerPatchNotice returns a notice when a newer patch of the line of version
+// has been released. The releases of a line are checked, and the notice
+// returned, at most once per cache TTL, a day by default. A failed check counts
+// as one, and the releases are given noticeTimeout to be listed, so that the
+// notice doesn't delay the builds when the repository is unreachable or slow.
+func (manager *Manager) NewerPatchNotice(ctx context.Context, version *Version) (string, error) {
+	noticesPath := filepath.Join(manager.cacheDirectory(), noticesFileName)
+	checks := map[string]time.Time{}
+	if content, err := ioutil.ReadFile(noticesPath); err == nil {
+		json.Unmarshal(content, &checks)
+	}
+	line := fmt.Sprintf("v%d.%d", version.major, version.minor)
+	if now().Sub(checks[line]) < manager.cacheTTL || manager.cachePolicy == CacheOffline {
+		return "", nil
+	}
+	checks[line] = now()
+	content, err := json.Marshal(checks)
+	if err != nil {
+		return "", err
+	}
+	if err = ioutil.WriteFile(noticesPath, content, 0644); err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, noticeTimeout)
+	defer cancel()
+	outdated, err := manager.CheckOutdated(ctx, version)
+	if err != nil {
+		return "", err
+	}
+	if outdated.LatestPatch == outdated.Current {
+		return "", nil
+	}
+	return fmt.Sprintf("hugo %s has been released in the %s line, %s is used", outdated.LatestPatch, line, outdated.Current), nil
+}
+
+// publishedVersions lists the versions of the releases, without the prereleases and drafts.
+func (manager *Manager) publishedVersions(ctx context.Context) ([]hugoversion.Version, error) {
+	releases, err := manager.repository.ListReleases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := []hugoversion.Version{}
+	for _, release := range releases {
+		if release.GetPrerelease() || release.GetDraft() {
+			continue
+		}
+		if value, err := hugoversion.Parse(release.GetName()); err == nil {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
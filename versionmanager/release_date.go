@@ -0,0 +1,35 @@
+package versionmanager
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ResolveCommitDate replaces @commit by the date of the HEAD commit of the
+// git repository holding directory, keeping the edition of the spec.
+func (resolution *SpecResolution) ResolveCommitDate(directory string) error {
+	if !strings.HasPrefix(resolution.Spec, CommitDate) {
+		return nil
+	}
+	date, err := commitDate(directory)
+	if err != nil {
+		return err
+	}
+	resolution.Spec = "@" + date + strings.TrimPrefix(resolution.Spec, CommitDate)
+	return nil
+}
+
+// commitDate returns the committer date of HEAD, in the form of 2023-06-01.
+func commitDate(directory string) (string, error) {
+	command := exec.Command("git", "log", "-1", "--format=%cd", "--date=short", "HEAD")
+	command.Dir = directory
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return "", fmt.Errorf("the date of the HEAD commit of %s can't be read for %s: %s", directory, CommitDate, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,124 @@
+package versionmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// releaseNotesFileName is the cache of the release notes, in the cache directory.
+const releaseNotesFileName = "release-notes.json"
+
+type ReleaseNote struct {
+	Version     string    `json:"version"`
+	PublishedAt time.Time `json:"published_at"`
+	Body        string    `json:"body"`
+}
+
+// URL returns the page of the release on GitHub.
+func (note ReleaseNote) URL() string {
+	return releaseNotesURL(note.Version)
+}
+
+type releaseNotesCache struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Notes     []ReleaseNote `json:"notes"`
+}
+
+// ReleaseNotes returns the notes of the releases after from up to to, oldest
+// first. The missing parts of from are 0, to includes the releases it would
+// select and may be empty or latest. The notes are cached for the cache TTL, a day by default, or
+// until CacheRefresh, and only the cached ones are used with CacheOffline.
+func (manager *Manager) ReleaseNotes(ctx context.Context, from string, to string) ([]ReleaseNote, error) {
+	constraint, err := releaseRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := manager.allReleaseNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	selected := []ReleaseNote{}
+	for _, note := range notes {
+		if version, err := hugoversion.Parse(note.Version); err == nil && constraint.Check(version) {
+			selected = append(selected, note)
+		}
+	}
+	return selected, nil
+}
+
+// releaseRange returns the constraint selecting the releases after from up to to.
+func releaseRange(from string, to string) (hugoversion.Constraint, error) {
+	lower, err := hugoversion.Parse(from)
+	if err != nil {
+		return nil, &InvalidVersionSpecError{Spec: from}
+	}
+	constraint := hugoversion.Constraint{{Operator: ">", Version: lower}}
+	if to == "" || to == "latest" {
+		return constraint, nil
+	}
+	upper, precision, err := parseCoreVersion(to)
+	if err != nil {
+		return nil, err
+	}
+	bound := versionConstraint(upper, precision)
+	if precision == patch {
+		constraint = append(constraint, hugoversion.Comparison{Operator: "<=", Version: bound[0].Version})
+	} else {
+		constraint = append(constraint, bound[1])
+	}
+	if !constraint.Satisfiable() {
+		return nil, fmt.Errorf("the range %s..%s is empty", from, to)
+	}
+	return constraint, nil
+}
+
+// allReleaseNotes returns the notes of every published release, oldest first.
+func (manager *Manager) allReleaseNotes(ctx context.Context) ([]ReleaseNote, error) {
+	cachePath := filepath.Join(manager.cacheDirectory(), releaseNotesFileName)
+	cache := new(releaseNotesCache)
+	if content, err := ioutil.ReadFile(cachePath); err == nil {
+		if err = json.Unmarshal(content, cache); err != nil {
+			cache = new(releaseNotesCache)
+		}
+	}
+	isFresh := now().Sub(cache.FetchedAt) < manager.cacheTTL && manager.cachePolicy != CacheRefresh
+	if isFresh || manager.cachePolicy == CacheOffline {
+		if cache.FetchedAt.IsZero() {
+			return nil, &OfflineError{Reason: "the release notes haven't been fetched yet and the network can't be used"}
+		}
+		return cache.Notes, nil
+	}
+	releases, err := manager.repository.ListReleases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notes := []ReleaseNote{}
+	values := map[string]hugoversion.Version{}
+	for _, release := range releases {
+		if release.GetPrerelease() || release.GetDraft() {
+			continue
+		}
+		value, err := hugoversion.Parse(release.GetName())
+		if err != nil {
+			continue
+		}
+		values[value.String()] = value
+		notes = append(notes, ReleaseNote{Version: value.String(), PublishedAt: release.GetPublishedAt(), Body: strings.TrimSpace(release.GetBody())})
+	}
+	sort.Slice(notes, func(i, j int) bool { return values[notes[i].Version].Less(values[notes[j].Version]) })
+	cache = &releaseNotesCache{FetchedAt: now(), Notes: notes}
+	if content, err := json.Marshal(cache); err == nil {
+		if err = ioutil.WriteFile(cachePath, content, 0644); err != nil {
+			manager.logger.Info("the release notes can't be cached", "error", err)
+		}
+	}
+	return notes, nil
+}
@@ -2,38 +2,76 @@ package versionmanager
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
+	"time"
 
 	"github.com/google/go-github/v31/github"
 	"github.com/pkg/errors"
 )
 
+// RepositoryClient gives access to the releases of a repository, the requests
+// are abandoned when ctx is done.
 type RepositoryClient interface {
-	GetLatestRelease() (Release, error)
-	GetReleaseByTag(tag string) (Release, error)
-	GetPreviousRelease(tag string) (Release, error)
+	GetLatestRelease(ctx context.Context) (Release, error)
+	GetReleaseByTag(ctx context.Context, tag string) (Release, error)
+	GetPreviousRelease(ctx context.Context, tag string) (Release, error)
+	// ListReleases returns every release, the latest first.
+	ListReleases(ctx context.Context) ([]Release, error)
 }
 
 type Release interface {
 	GetName() string
+	GetTagName() string
+	// GetPublishedAt is the zero time when the release hasn't been published.
+	GetPublishedAt() time.Time
+	GetPrerelease() bool
+	GetDraft() bool
+	// GetBody returns the release notes.
+	GetBody() string
 	GetAssetByName(name string) (Asset, error)
+	ListAssets() []Asset
 }
 
 type Asset interface {
 	GetName() string
 	GetDownloadUrl() string
+	// GetSize is -1 when the size is unknown.
+	GetSize() int64
+	GetContentType() string
+	// GetDigest returns the digest published by the backend in the form of
+	// algorithm:hex, such as sha256:..., or an empty string.
+	GetDigest() string
 }
 
+// RateLimitedClient is implemented by the repository clients whose API has a request quota.
+type RateLimitedClient interface {
+	GetRateLimit(ctx context.Context) (*RateLimit, error)
+}
+
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// apiTimeout bounds every request made to a repository API.
+const apiTimeout = 30 * time.Second
+
 type RepositoryType int
 
 var Github = RepositoryType(1)
 
+// NewRepositoryService returns the client of a repository, NewBackend gives access to every registered backend.
 func NewRepositoryService(repoType RepositoryType, organisation string, repository string, username string, password string) RepositoryClient {
 	switch repoType {
 	case Github:
-		return newGithubRepository(&http.Client{}, organisation, repository)
+		client, err := NewBackend(BackendConfig{Type: "github", Owner: organisation, Repository: repository})
+		if err != nil {
+			panic(err)
+		}
+		return client
 	default:
 		panic("no service for this repository type")
 	}
@@ -48,8 +86,13 @@ type githubRepositoryServiceInterface interface {
 	ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
 }
 
+type githubRateLimitServiceInterface interface {
+	RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error)
+}
+
 type githubRepository struct {
 	service      githubRepositoryServiceInterface
+	rateLimits   githubRateLimitServiceInterface
 	organisation string
 	repository   string
 }
@@ -63,26 +106,37 @@ type githubAsset struct {
 }
 
 func newGithubRepository(client *http.Client, organisation string, repository string) (repo *githubRepository) {
+	githubClient := github.NewClient(client)
 	return &githubRepository{
 		organisation: organisation,
 		repository:   repository,
-		service:      github.NewClient(client).Repositories,
+		service:      githubClient.Repositories,
+		rateLimits:   githubClient,
 	}
 }
 
-func (repo *githubRepository) GetLatestRelease() (Release, error) {
-	release, _, err := repo.service.GetLatestRelease(context.TODO(), repo.organisation, repo.repository)
-	return &githubRelease{release}, err
+func (repo *githubRepository) GetLatestRelease(ctx context.Context) (Release, error) {
+	release, _, err := repo.service.GetLatestRelease(ctx, repo.organisation, repo.repository)
+	if err != nil {
+		return nil, githubError(err, "latest")
+	}
+	return &githubRelease{release}, nil
 }
 
-func (repo *githubRepository) GetReleaseByTag(tag string) (Release, error) {
-	release, _, err := repo.service.GetReleaseByTag(context.TODO(), repo.organisation, repo.repository, tag)
-	return &githubRelease{release}, err
+func (repo *githubRepository) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
+	release, _, err := repo.service.GetReleaseByTag(ctx, repo.organisation, repo.repository, tag)
+	if err != nil {
+		return nil, githubError(err, tag)
+	}
+	return &githubRelease{release}, nil
 }
 
-func (repo *githubRepository) GetPreviousRelease(tag string) (Release, error) {
-	pager, err := repo.newReleasePager()
-	release, err := repo.GetReleaseByTag(tag)
+func (repo *githubRepository) GetPreviousRelease(ctx context.Context, tag string) (Release, error) {
+	pager, err := repo.newReleasePager(ctx)
+	if err != nil {
+		return nil, githubError(err, "")
+	}
+	release, err := repo.GetReleaseByTag(ctx, tag)
 	if err != nil {
 		return nil, errors.Cause(err)
 	}
@@ -97,27 +151,85 @@ func (repo *githubRepository) GetPreviousRelease(tag string) (Release, error) {
 	}
 	if pointerIndex == (len(pager.currentReleases) - 1) {
 		if !pager.hasMore() {
-			return nil, fmt.Errorf("No previous release found for %s", pointer.GetName())
+			return nil, &VersionNotFoundError{Version: tag, Reason: "no previous release found"}
 		}
 		if err = pager.getNextPage(); err != nil {
-			return nil, err
+			return nil, githubError(err, "")
 		}
 		return &githubRelease{pager.currentReleases[0]}, nil
 	}
 	return &githubRelease{pager.currentReleases[pointerIndex+1]}, nil
 }
 
+// releasesPerPage is the largest page accepted by the GitHub API.
+const releasesPerPage = 100
+
+func (repo *githubRepository) ListReleases(ctx context.Context) ([]Release, error) {
+	releases := []Release{}
+	opt := &github.ListOptions{Page: 1, PerPage: releasesPerPage}
+	for opt.Page != 0 {
+		page, response, err := repo.service.ListReleases(ctx, repo.organisation, repo.repository, opt)
+		if err != nil {
+			return nil, githubError(err, "")
+		}
+		for _, release := range page {
+			releases = append(releases, &githubRelease{release})
+		}
+		opt.Page = response.NextPage
+	}
+	return releases, nil
+}
+
+func (repo *githubRepository) GetRateLimit(ctx context.Context) (*RateLimit, error) {
+	limits, _, err := repo.rateLimits.RateLimits(ctx)
+	if err != nil {
+		return nil, githubError(err, "")
+	}
+	core := limits.GetCore()
+	return &RateLimit{Limit: core.Limit, Remaining: core.Remaining, Reset: core.Reset.Time}, nil
+}
+
 func (release *githubRelease) GetName() string {
 	return release.RepositoryRelease.GetName()
 }
 
+func (release *githubRelease) GetPublishedAt() time.Time {
+	return release.RepositoryRelease.GetPublishedAt().Time
+}
+
+func (release *githubRelease) ListAssets() []Asset {
+	assets := make([]Asset, 0, len(release.Assets))
+	for _, asset := range release.Assets {
+		assets = append(assets, githubAsset{asset})
+	}
+	return assets
+}
+
 func (release *githubRelease) GetAssetByName(name string) (Asset, error) {
 	for _, asset := range release.Assets {
 		if asset.GetName() == name {
 			return githubAsset{asset}, nil
 		}
 	}
-	return nil, fmt.Errorf("asset %s not found in release %s", name, release.GetName())
+	return nil, &AssetNotFoundError{Asset: name, Release: release.GetName()}
+}
+
+// githubError converts the errors of the github client into the errors of this
+// package, tag is the release which was requested, if any.
+func githubError(err error, tag string) error {
+	switch githubErr := err.(type) {
+	case *github.RateLimitError:
+		return &RateLimitError{Reset: githubErr.Rate.Reset.Time, Err: err}
+	case *github.AbuseRateLimitError:
+		return &RateLimitError{Err: err}
+	case *github.ErrorResponse:
+		if tag != "" && githubErr.Response != nil && githubErr.Response.StatusCode == http.StatusNotFound {
+			return &VersionNotFoundError{Version: tag, Reason: "no release has this tag"}
+		}
+	case *url.Error:
+		return &OfflineError{Reason: "the repository is unreachable", Err: err}
+	}
+	return err
 }
 
 func (asset githubAsset) GetName() string {
@@ -127,16 +239,30 @@ func (asset githubAsset) GetDownloadUrl() string {
 	return asset.ReleaseAsset.GetBrowserDownloadURL()
 }
 
+func (asset githubAsset) GetSize() int64 {
+	if asset.ReleaseAsset.Size == nil {
+		return -1
+	}
+	return int64(asset.ReleaseAsset.GetSize())
+}
+
+// GetDigest returns an empty string, the digests aren't known to this version of the GitHub client.
+func (asset githubAsset) GetDigest() string {
+	return ""
+}
+
 type releasePager struct {
 	*githubRepository
+	ctx             context.Context
 	currentReleases []*github.RepositoryRelease
 	currentResponse *github.Response
 	opt             *github.ListOptions
 }
 
-func (repo *githubRepository) newReleasePager() (*releasePager, error) {
+func (repo *githubRepository) newReleasePager(ctx context.Context) (*releasePager, error) {
 	pager := new(releasePager)
 	pager.githubRepository = repo
+	pager.ctx = ctx
 	err := pager.toStart()
 	return pager, err
 }
@@ -150,7 +276,7 @@ func (pager *releasePager) hasMore() bool {
 
 func (pager *releasePager) toStart() (err error) {
 	pager.opt = &github.ListOptions{Page: 1}
-	pager.currentReleases, pager.currentResponse, err = pager.service.ListReleases(context.TODO(), pager.organisation, pager.repository, pager.opt)
+	pager.currentReleases, pager.currentResponse, err = pager.service.ListReleases(pager.ctx, pager.organisation, pager.repository, pager.opt)
 	return
 }
 
@@ -162,7 +288,7 @@ func (pager *releasePager) getNextPage() (err error) {
 		panic("no more releases to be found")
 	}
 	pager.opt.Page++
-	pager.currentReleases, pager.currentResponse, err = pager.service.ListReleases(context.TODO(), pager.organisation, pager.repository, pager.opt)
+	pager.currentReleases, pager.currentResponse, err = pager.service.ListReleases(pager.ctx, pager.organisation, pager.repository, pager.opt)
 	return
 }
 
@@ -173,7 +299,7 @@ func (pager *releasePager) moveToPageContainingRelease(release *githubRelease) (
 		olderReleaseOnPage := pager.currentReleases[len(pager.currentReleases)-1]
 		isFound = release.GetCreatedAt().After(olderReleaseOnPage.GetCreatedAt().Time)
 		if !pager.hasMore() {
-			return fmt.Errorf("The release %s version has not been found", release.GetName())
+			return &VersionNotFoundError{Version: release.GetName(), Reason: "the release is not listed"}
 		}
 		if err = pager.getNextPage(); err != nil {
 			return err
@@ -189,5 +315,5 @@ func (pager *releasePager) findReleaseOnPage(release *githubRelease) (index int,
 	if i < len(pager.currentReleases) && release.GetCreatedAt().Equal(pager.currentReleases[i].GetCreatedAt()) {
 		return i, nil
 	}
-	return -1, fmt.Errorf("The version %s version has not been found", release.GetName())
+	return -1, &VersionNotFoundError{Version: release.GetName(), Reason: "the release is not listed"}
 }
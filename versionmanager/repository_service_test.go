@@ -4,6 +4,7 @@ import (
 	context "context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	github "github.com/google/go-github/v31/github"
@@ -23,7 +24,7 @@ func TestGetLatestRelease(t *testing.T) {
 		repository:   "gohugo",
 	}
 	serviceMock.EXPECT().GetLatestRelease(context.TODO(), repo.organisation, repo.repository).Return(new(github.RepositoryRelease), nil, nil)
-	repo.GetLatestRelease()
+	repo.GetLatestRelease(context.TODO())
 }
 
 func TestGetReleaseByTag(t *testing.T) {
@@ -44,7 +45,7 @@ func getReleaseByTagHappyFlow(t *testing.T) {
 		repository:   "gohugo",
 	}
 	serviceMock.EXPECT().GetReleaseByTag(context.TODO(), repo.organisation, repo.repository, tag)
-	repo.GetReleaseByTag(tag)
+	repo.GetReleaseByTag(context.TODO(), tag)
 }
 
 func getReleaseByTagError(t *testing.T) {
@@ -63,7 +64,7 @@ func getReleaseByTagError(t *testing.T) {
 	}
 	serviceMock.EXPECT().GetReleaseByTag(context.TODO(), repo.organisation, repo.repository, tag).Return(nil, nil, expectedError)
 
-	_, err := repo.GetReleaseByTag(tag)
+	_, err := repo.GetReleaseByTag(context.TODO(), tag)
 	assert := assert.New(t)
 	assert.Equal(expectedError, err, "error returned by githubrepo should be passed as it is")
 }
@@ -88,7 +89,7 @@ func whenReleaseWithTagReturnError_thenTheErrorShouldBeReturned(t *testing.T) {
 		organisation: "hugo",
 		repository:   "gohugo",
 	}
-	pager, _ := repo.newReleasePager()
+	pager, _ := repo.newReleasePager(context.TODO())
 	fmt.Printf("%+v\n", pager)
 }
 
@@ -103,12 +104,30 @@ func TestGetName_forAsset(t *testing.T)   {}
 
 func TestGetDownloadUrl(t *testing.T) {}
 
-func TesthasMore(t *testing.T) {}
+func TestListAssets(t *testing.T) {
+	name := "hugo_0.72.0_Linux-64bit.tar.gz"
+	size := 1024
+	publishedAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	release := &githubRelease{&github.RepositoryRelease{
+		PublishedAt: &github.Timestamp{Time: publishedAt},
+		Assets:      []*github.ReleaseAsset{{Name: &name, Size: &size}, {}},
+	}}
+
+	assets := release.ListAssets()
+	assert.Equal(t, 2, len(assets))
+	assert.Equal(t, name, assets[0].GetName())
+	assert.Equal(t, int64(1024), assets[0].GetSize())
+	assert.Equal(t, int64(-1), assets[1].GetSize(), "an unknown size should be -1")
+	assert.Equal(t, publishedAt, release.GetPublishedAt())
+	assert.True(t, (&githubRelease{new(github.RepositoryRelease)}).GetPublishedAt().IsZero())
+}
+
+func TestHasMore(t *testing.T) {}
 
-func TesttoStart(t *testing.T) {}
+func TestToStart(t *testing.T) {}
 
-func TestgetNextPage(t *testing.T) {}
+func TestGetNextPage(t *testing.T) {}
 
-func TestmoveToPageContainingRelease(t *testing.T) {}
+func TestMoveToPageContainingRelease(t *testing.T) {}
 
-func TestfindReleaseOnPage(t *testing.T) {}
+func TestFindReleaseOnPage(t *testing.T) {}
@@ -0,0 +1,268 @@
+package versionmanager
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// Requirement is a constraint on the hugo version demanded by a component of
+// a site: the project itself, a theme or a module.
+type Requirement struct {
+	Component  string
+	File       string
+	Constraint hugoversion.Constraint
+	Extended   bool
+}
+
+func (requirement Requirement) String() string {
+	demand := requirement.Constraint.String()
+	if demand == "" {
+		demand = "any version"
+	}
+	if requirement.Extended {
+		demand += " extended"
+	}
+	return fmt.Sprintf("%s (%s) requires %s", requirement.Component, requirement.File, demand)
+}
+
+// ComponentRequirements reads the requirements of the themes of the site in
+// sourceDirectory, including the themes of the themes, and of its vendored
+// modules. The min_version of theme.toml and the module.hugoVersion section
+// of the configuration of the components are read.
+func ComponentRequirements(sourceDirectory string, configFiles []string) ([]Requirement, error) {
+	scanner := &componentScanner{themesDirectory: filepath.Join(sourceDirectory, "themes"), scanned: map[string]bool{}}
+	themes := []string{}
+	for _, configFile := range (&siteConfigSource{sourceDirectory: sourceDirectory, configFiles: configFiles}).candidates() {
+		config, err := readConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		if themesDirectory, found := configValue(config, "themesDir"); found {
+			scanner.themesDirectory = fmt.Sprint(themesDirectory)
+			if !filepath.IsAbs(scanner.themesDirectory) {
+				scanner.themesDirectory = filepath.Join(sourceDirectory, scanner.themesDirectory)
+			}
+		}
+		themes = append(themes, configThemes(config)...)
+	}
+	for _, theme := range themes {
+		if err := scanner.scanTheme(theme); err != nil {
+			return nil, err
+		}
+	}
+	vendorDirectory := filepath.Join(sourceDirectory, "_vendor")
+	modules, err := vendoredModules(vendorDirectory)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range modules {
+		if err := scanner.scan("module "+module, filepath.Join(vendorDirectory, filepath.FromSlash(module))); err != nil {
+			return nil, err
+		}
+	}
+	return scanner.requirements, nil
+}
+
+// SiteRequirements returns the requirement declared in the [module.hugoVersion]
+// section of the configuration of the site itself, if any.
+func SiteRequirements(sourceDirectory string, configFiles []string) ([]Requirement, error) {
+	for _, configFile := range (&siteConfigSource{sourceDirectory: sourceDirectory, configFiles: configFiles}).candidates() {
+		siteRequirement, err := ReadSiteRequirement(configFile)
+		if err != nil || siteRequirement == nil {
+			continue
+		}
+		constraint, err := siteRequirement.Constraint()
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid module.hugoVersion: %w", configFile, err)
+		}
+		return []Requirement{{Component: "site", File: configFile, Constraint: constraint, Extended: siteRequirement.Extended}}, nil
+	}
+	return nil, nil
+}
+
+type componentScanner struct {
+	themesDirectory string
+	scanned         map[string]bool
+	requirements    []Requirement
+}
+
+func (scanner *componentScanner) scanTheme(theme string) error {
+	return scanner.scan("theme "+theme, filepath.Join(scanner.themesDirectory, theme))
+}
+
+func (scanner *componentScanner) scan(component string, directory string) error {
+	if scanner.scanned[directory] {
+		return nil
+	}
+	scanner.scanned[directory] = true
+	if info, err := os.Stat(directory); err != nil || !info.IsDir() {
+		return nil
+	}
+	if themeFile, found := findSiteConfig(directory, []string{"theme"}); found {
+		config, err := readConfigFile(themeFile)
+		if err != nil {
+			return err
+		}
+		if minVersion, found := configValue(config, "min_version"); found {
+			constraint, err := hugoversion.ParseConstraint(">=" + strings.TrimPrefix(fmt.Sprint(minVersion), "v"))
+			if err != nil {
+				return fmt.Errorf("%s: invalid min_version: %w", themeFile, err)
+			}
+			scanner.requirements = append(scanner.requirements, Requirement{Component: component, File: themeFile, Constraint: constraint})
+		}
+	}
+	themes := []string{}
+	for _, configFile := range (&siteConfigSource{sourceDirectory: directory}).candidates() {
+		config, err := readConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		themes = append(themes, configThemes(config)...)
+		siteRequirement, err := ReadSiteRequirement(configFile)
+		if err != nil || siteRequirement == nil {
+			continue
+		}
+		constraint, err := siteRequirement.Constraint()
+		if err != nil {
+			return fmt.Errorf("%s: invalid module.hugoVersion: %w", configFile, err)
+		}
+		scanner.requirements = append(scanner.requirements, Requirement{Component: component, File: configFile, Constraint: constraint, Extended: siteRequirement.Extended})
+	}
+	for _, theme := range themes {
+		if err := scanner.scanTheme(theme); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configThemes returns the themes of a configuration, given as a list or as
+// a comma separated string.
+func configThemes(config map[string]interface{}) []string {
+	value, found := configValue(config, "theme")
+	if !found {
+		return nil
+	}
+	items := []interface{}{value}
+	if list, ok := value.([]interface{}); ok {
+		items = list
+	}
+	themes := []string{}
+	for _, item := range items {
+		for _, theme := range strings.Split(fmt.Sprint(item), ",") {
+			if theme = strings.TrimSpace(theme); theme != "" {
+				themes = append(themes, theme)
+			}
+		}
+	}
+	return themes
+}
+
+// vendoredModules lists the modules of _vendor/modules.txt, written by hugo
+// mod vendor with a line in the form of "# <path> <version>" per module.
+func vendoredModules(vendorDirectory string) ([]string, error) {
+	file, err := os.Open(filepath.Join(vendorDirectory, "modules.txt"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	modules := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "#" {
+			modules = append(modules, fields[1])
+		}
+	}
+	return modules, scanner.Err()
+}
+
+// Constrain combines the spec with the requirements of the components of the
+// site. An exact version is kept as long as it satisfies them, latest-stable
+// and a date select among the releases satisfying them, any other spec is
+// replaced by the constraint they form together.
+func (resolution *SpecResolution) Constrain(requirements ...Requirement) error {
+	if len(requirements) == 0 {
+		return nil
+	}
+	desiredVersion, edition, err := extractEdition(resolution.Spec)
+	if err != nil {
+		return err
+	}
+	project := Requirement{Component: "project", File: resolution.Source, Extended: edition != hugoversion.Standard}
+	isExact := false
+	alias := ""
+	switch {
+	case desiredVersion == "latest":
+	case desiredVersion == LatestStable:
+		alias = LatestStable
+	case strings.HasPrefix(desiredVersion, "@"):
+		alias = desiredVersion
+	case hugoversion.IsConstraint(desiredVersion):
+		if project.Constraint, err = hugoversion.ParseConstraint(desiredVersion); err != nil {
+			return &InvalidVersionSpecError{Spec: resolution.Spec}
+		}
+	default:
+		core, precision, err := parseCoreVersion(desiredVersion)
+		if err != nil {
+			return err
+		}
+		project.Constraint = versionConstraint(core, precision)
+		isExact = precision == patch
+	}
+	resolution.Requirements = append([]Requirement{project}, requirements...)
+	constraint := project.Constraint
+	for _, requirement := range requirements {
+		constraint = constraint.Intersect(requirement.Constraint)
+		if requirement.Extended && edition == hugoversion.Standard {
+			edition = hugoversion.Extended
+		}
+	}
+	if !constraint.Satisfiable() {
+		return &UnsatisfiableError{Requirements: resolution.Requirements}
+	}
+	spec := desiredVersion
+	if !isExact && len(constraint) > 0 {
+		spec = constraint.String()
+		if alias != "" {
+			spec = alias + "," + spec
+		}
+	}
+	if edition != hugoversion.Standard {
+		spec += "-" + edition.String()
+	}
+	resolution.Spec = spec
+	return nil
+}
+
+// ExplainError reports the requirements of the site when no release satisfies them.
+func (resolution *SpecResolution) ExplainError(err error) error {
+	if len(resolution.Requirements) > 1 && errors.Is(err, ErrVersionNotFound) {
+		return &UnsatisfiableError{Requirements: resolution.Requirements, Err: err}
+	}
+	return err
+}
+
+// versionConstraint returns the constraint matching the versions a spec with this precision selects from.
+func versionConstraint(version *coreVersion, precision versionPrecision) hugoversion.Constraint {
+	lower := hugoversion.Version{Major: version.major, Minor: version.minor, Patch: version.patch}
+	upper := lower
+	switch precision {
+	case major:
+		upper = hugoversion.Version{Major: version.major + 1}
+	case minor:
+		upper = hugoversion.Version{Major: version.major, Minor: version.minor + 1}
+	default:
+		return hugoversion.Constraint{{Operator: "=", Version: lower}}
+	}
+	return hugoversion.Constraint{{Operator: ">=", Version: lower}, {Operator: "<", Version: upper}}
+}
@@ -0,0 +1,87 @@
+package versionmanager
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentRequirements(t *testing.T) {
+	assert := assert.New(t)
+	site, err := ioutil.TempDir("", "hugo-site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(site)
+	writeSiteFile(t, filepath.Join(site, "hugo.toml"), "theme = [\"ananke\"]\n")
+	writeSiteFile(t, filepath.Join(site, "themes", "ananke", "theme.toml"), "name = \"Ananke\"\nmin_version = \"0.84.0\"\n")
+	writeSiteFile(t, filepath.Join(site, "themes", "ananke", "config.yaml"), "theme: search\n")
+	writeSiteFile(t, filepath.Join(site, "themes", "search", "hugo.toml"), "[module.hugoVersion]\nextended = true\nmax = \"0.120.0\"\n")
+	writeSiteFile(t, filepath.Join(site, "_vendor", "modules.txt"), "# github.com/owner/shortcodes v1.0.0\n")
+	writeSiteFile(t, filepath.Join(site, "_vendor", "github.com", "owner", "shortcodes", "config", "_default", "module.toml"), "[hugoVersion]\nmin = \"0.90.0\"\n")
+
+	requirements, err := ComponentRequirements(site, nil)
+	assert.Nil(err)
+	descriptions := []string{}
+	for _, requirement := range requirements {
+		descriptions = append(descriptions, requirement.Component+": "+requirement.Constraint.String())
+	}
+	assert.Equal([]string{
+		"theme ananke: >=0.84.0",
+		"theme search: <=0.120.0",
+		"module github.com/owner/shortcodes: >=0.90.0",
+	}, descriptions)
+	assert.True(requirements[1].Extended)
+}
+
+func TestConstrain(t *testing.T) {
+	assert := assert.New(t)
+	theme := Requirement{Component: "theme ananke", File: "theme.toml", Constraint: mustParseConstraint(t, ">=0.84.0")}
+	extendedModule := Requirement{Component: "module search", File: "hugo.toml", Constraint: mustParseConstraint(t, "<=0.120.0"), Extended: true}
+
+	resolution := &SpecResolution{Spec: "latest", Source: "default"}
+	assert.Nil(resolution.Constrain(theme, extendedModule))
+	assert.Equal(">=0.84.0,<=0.120.0-extended", resolution.Spec)
+	assert.Equal(3, len(resolution.Requirements), "the project should be part of the requirements")
+
+	resolution = &SpecResolution{Spec: "0.90", Source: "file .hugo-version"}
+	assert.Nil(resolution.Constrain(theme))
+	assert.Equal(">=0.90.0,<0.91.0,>=0.84.0", resolution.Spec)
+
+	resolution = &SpecResolution{Spec: "0.110.0-extended", Source: "file .hugo-version"}
+	assert.Nil(resolution.Constrain(theme, extendedModule))
+	assert.Equal("0.110.0-extended", resolution.Spec, "an exact version satisfying the requirements should be kept")
+
+	resolution = &SpecResolution{Spec: "0.72.3", Source: "file .hugo-version"}
+	err := resolution.Constrain(theme)
+	assert.True(errors.Is(err, ErrVersionNotFound))
+	assert.Contains(err.Error(), "project (file .hugo-version) requires =0.72.3")
+	assert.Contains(err.Error(), "theme ananke (theme.toml) requires >=0.84.0")
+
+	resolution = &SpecResolution{Spec: "latest-stable-extended", Source: "default"}
+	assert.Nil(resolution.Constrain(theme))
+	assert.Equal("latest-stable,>=0.84.0-extended", resolution.Spec, "the release age should still be applied")
+
+	resolution = &SpecResolution{Spec: "@2023-06-01", Source: "flag --hugo-version"}
+	assert.Nil(resolution.Constrain(theme, extendedModule))
+	assert.Equal("@2023-06-01,>=0.84.0,<=0.120.0-extended", resolution.Spec, "the release of the date should satisfy the requirements")
+	err = resolution.ExplainError(&VersionNotFoundError{Version: "@2023-06-01,>=0.84.0,<=0.120.0"})
+	assert.True(errors.As(err, new(*UnsatisfiableError)), "no release of the date satisfying the requirements should be reported")
+
+	resolution = &SpecResolution{Spec: "latest", Source: "default"}
+	assert.Nil(resolution.Constrain())
+	assert.Equal("latest", resolution.Spec, "the spec should be kept without requirements")
+}
+
+func mustParseConstraint(t *testing.T, text string) hugoversion.Constraint {
+	constraint, err := hugoversion.ParseConstraint(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return constraint
+}
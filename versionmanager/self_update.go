@@ -0,0 +1,115 @@
+package versionmanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// The repository the wrapper itself is released from.
+const (
+	WrapperOwner      = "TiboStev"
+	WrapperRepository = "hugo-wrapper"
+)
+
+// WrapperChecksumsName is the asset listing the sha256 sums of the executables of a release of the wrapper.
+const WrapperChecksumsName = WrapperRepository + "_checksums.txt"
+
+// WrapperRelease is a release of the wrapper, with its executable for a platform.
+type WrapperRelease struct {
+	Version   string
+	asset     Asset
+	checksums Asset
+}
+
+// WrapperAssetName returns the name of the executable of the wrapper built
+// for a platform by gox, such as hugo-wrapper_linux_amd64.
+func WrapperAssetName(goos string, goarch string) string {
+	name := fmt.Sprintf("%s_%s_%s", WrapperRepository, goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// LatestWrapperRelease returns the latest release of the wrapper published in
+// repository, such as the github backend of WrapperOwner/WrapperRepository.
+func LatestWrapperRelease(ctx context.Context, repository RepositoryClient, goos string, goarch string) (*WrapperRelease, error) {
+	release, err := repository.GetLatestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assetName := WrapperAssetName(goos, goarch)
+	asset, err := release.GetAssetByName(assetName)
+	if err != nil || asset == nil {
+		return nil, &AssetNotFoundError{Asset: assetName, Release: release.GetTagName()}
+	}
+	wrapperRelease := &WrapperRelease{Version: release.GetTagName(), asset: asset}
+	if checksums, err := release.GetAssetByName(WrapperChecksumsName); err == nil && checksums != nil {
+		wrapperRelease.checksums = checksums
+	}
+	return wrapperRelease, nil
+}
+
+// IsNewerThan tells if the release is newer than current, the version of the
+// running wrapper. Both are compared as semantic versions, an error being
+// returned when one doesn't parse, such as the version of a development build.
+func (release *WrapperRelease) IsNewerThan(current string) (bool, error) {
+	latest, err := hugoversion.Parse(release.Version)
+	if err != nil {
+		return false, fmt.Errorf("the release %s can't be compared: %w", release.Version, err)
+	}
+	running, err := hugoversion.Parse(current)
+	if err != nil {
+		return false, fmt.Errorf("the running wrapper can't be compared with %s: %w", release.Version, err)
+	}
+	return running.Less(latest), nil
+}
+
+// Download writes the executable to path and checks it against the checksums
+// published with the release, or against the sha256 digest given by the
+// repository. verified is false when neither is published, the download
+// must then not be trusted.
+func (release *WrapperRelease) Download(ctx context.Context, client *http.Client, path string) (verified bool, err error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, size, err := openURL(ctx, client, release.asset.GetDownloadUrl())
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return false, err
+	}
+	written, err := io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, err
+	}
+	expected := release.asset.GetSize()
+	if expected < 0 {
+		expected = size
+	}
+	if expected >= 0 && written != expected {
+		return false, fmt.Errorf("the download of %s is incomplete, %d bytes out of %d", release.asset.GetName(), written, expected)
+	}
+	if release.checksums == nil {
+		return verifyAssetDigest(release.asset, path)
+	}
+	checksums, err := fetch(ctx, client, release.checksums.GetDownloadUrl())
+	if err != nil {
+		return false, err
+	}
+	if err = verifyChecksum(path, release.asset.GetName(), checksums); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,86 @@
+package versionmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapperRelease(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	executable := []byte("#!/bin/sh\n")
+	sum := sha256.Sum256(executable)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/releases.json":
+			fmt.Fprint(writer, `[{"name": "v1.2.0", "tag_name": "v1.2.0", "assets": [
+				{"name": "hugo-wrapper_linux_amd64"},
+				{"name": "hugo-wrapper_linux_arm64"},
+				{"name": "hugo-wrapper_windows_amd64.exe"},
+				{"name": "hugo-wrapper_checksums.txt"}
+			]}]`)
+			return
+		case "/v1.2.0/hugo-wrapper_checksums.txt":
+			fmt.Fprintf(writer, "%s  hugo-wrapper_linux_amd64\n%s  hugo-wrapper_linux_arm64\n", hex.EncodeToString(sum[:]), strings.Repeat("0", 64))
+			return
+		}
+		writer.Write(executable)
+	}))
+	defer server.Close()
+	directory, err := ioutil.TempDir("", "hugo-wrapper-update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	repository, err := NewBackend(BackendConfig{Type: "http-mirror", URL: server.URL})
+	assert.Nil(err)
+
+	release, err := LatestWrapperRelease(ctx, repository, "linux", "amd64")
+	assert.Nil(err)
+	assert.Equal("v1.2.0", release.Version)
+	path := filepath.Join(directory, "hugo-wrapper")
+	verified, err := release.Download(context.Background(), nil, path)
+	assert.Nil(err)
+	assert.True(verified)
+	content, _ := ioutil.ReadFile(path)
+	assert.Equal(executable, content)
+
+	release, err = LatestWrapperRelease(ctx, repository, "linux", "arm64")
+	assert.Nil(err)
+	_, err = release.Download(context.Background(), nil, path)
+	assert.True(errors.Is(err, ErrChecksumMismatch), "the executable should be checked against the checksums file")
+	release, err = LatestWrapperRelease(ctx, repository, "windows", "amd64")
+	assert.Nil(err)
+	_, err = release.Download(context.Background(), nil, path)
+	assert.True(errors.Is(err, ErrAssetNotFound), "the executable missing from the checksums file should be refused")
+
+	_, err = LatestWrapperRelease(ctx, repository, "darwin", "arm64")
+	assert.True(errors.Is(err, ErrAssetNotFound))
+}
+
+func TestWrapperReleaseIsNewer(t *testing.T) {
+	assert := assert.New(t)
+	release := &WrapperRelease{Version: "v1.10.0"}
+
+	for current, expected := range map[string]bool{"v1.9.2": true, "1.9.2": true, "v1.10.0-rc.1": true, "v1.10.0": false, "1.10.0": false, "v1.11.0": false} {
+		newer, err := release.IsNewerThan(current)
+		assert.Nil(err)
+		assert.Equal(expected, newer, current)
+	}
+	_, err := release.IsNewerThan("dev")
+	assert.NotNil(err, "a development build can't be compared")
+	_, err = (&WrapperRelease{Version: "nightly"}).IsNewerThan("v1.9.2")
+	assert.NotNil(err)
+}
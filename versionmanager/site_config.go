@@ -0,0 +1,207 @@
+package versionmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// siteConfigNames are the configuration files of a site looked for by hugo,
+// in its source directory or in config/_default.
+var siteConfigNames = []string{"hugo", "config"}
+
+var siteConfigExtensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// SiteRequirement is the hugo version required by a site, declared in the
+// [module.hugoVersion] section of its configuration.
+type SiteRequirement struct {
+	Min      string
+	Max      string
+	Extended bool
+}
+
+// Spec turns the requirement into a version spec, the newest release between
+// min and max being selected.
+func (requirement *SiteRequirement) Spec() string {
+	comparisons := []string{}
+	if requirement.Min != "" {
+		comparisons = append(comparisons, ">="+strings.TrimPrefix(requirement.Min, "v"))
+	}
+	if requirement.Max != "" {
+		comparisons = append(comparisons, "<="+strings.TrimPrefix(requirement.Max, "v"))
+	}
+	spec := strings.Join(comparisons, ",")
+	if spec == "" {
+		spec = "latest"
+	}
+	if requirement.Extended {
+		spec += "-extended"
+	}
+	return spec
+}
+
+// Constraint returns the versions allowed by the requirement.
+func (requirement *SiteRequirement) Constraint() (hugoversion.Constraint, error) {
+	spec, _, err := extractEdition(requirement.Spec())
+	if err != nil || spec == "latest" {
+		return nil, err
+	}
+	return hugoversion.ParseConstraint(spec)
+}
+
+type siteConfigSource struct {
+	sourceDirectory string
+	configFiles     []string
+	found           string
+}
+
+// NewSiteConfigSource returns the source reading the requirement of the site in
+// sourceDirectory. configFiles are the files given to hugo with --config, the
+// default configuration files of hugo are used when there are none.
+func NewSiteConfigSource(sourceDirectory string, configFiles []string) SpecSource {
+	return &siteConfigSource{sourceDirectory: sourceDirectory, configFiles: configFiles}
+}
+
+func (source *siteConfigSource) Name() string {
+	if source.found != "" {
+		return "site config " + source.found
+	}
+	return "site config"
+}
+
+func (source *siteConfigSource) Lookup() (string, bool, error) {
+	for _, configFile := range source.candidates() {
+		requirement, err := ReadSiteRequirement(configFile)
+		if err != nil {
+			return "", false, err
+		}
+		if requirement != nil {
+			source.found = configFile
+			return requirement.Spec(), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// candidates lists the existing configuration files, by order of precedence.
+func (source *siteConfigSource) candidates() []string {
+	candidates := []string{}
+	if len(source.configFiles) > 0 {
+		for _, configFile := range source.configFiles {
+			if !filepath.IsAbs(configFile) {
+				configFile = filepath.Join(source.sourceDirectory, configFile)
+			}
+			candidates = append(candidates, configFile)
+		}
+	} else if configFile, found := findSiteConfig(source.sourceDirectory, siteConfigNames); found {
+		candidates = append(candidates, configFile)
+	}
+	defaultDirectory := filepath.Join(source.sourceDirectory, "config", "_default")
+	for _, name := range append(siteConfigNames, "module") {
+		if configFile, found := findSiteConfig(defaultDirectory, []string{name}); found {
+			candidates = append(candidates, configFile)
+		}
+	}
+	return candidates
+}
+
+func findSiteConfig(directory string, names []string) (string, bool) {
+	for _, name := range names {
+		for _, extension := range siteConfigExtensions {
+			configFile := filepath.Join(directory, name+extension)
+			if info, err := os.Stat(configFile); err == nil && !info.IsDir() {
+				return configFile, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ReadSiteRequirement reads the requirement declared in a configuration file
+// of a site, it returns nil when the file doesn't declare one. The
+// module.* files of config/_default declare it at their top level.
+func ReadSiteRequirement(configFile string) (*SiteRequirement, error) {
+	config, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(filepath.Base(configFile), filepath.Ext(configFile)) != "module" {
+		config = configSection(config, "module")
+	}
+	section := configSection(config, "hugoVersion")
+	if section == nil {
+		return nil, nil
+	}
+	requirement := &SiteRequirement{}
+	for key, value := range section {
+		switch strings.ToLower(key) {
+		case "min":
+			requirement.Min = fmt.Sprint(value)
+		case "max":
+			requirement.Max = fmt.Sprint(value)
+		case "extended":
+			requirement.Extended, _ = value.(bool)
+		}
+	}
+	return requirement, nil
+}
+
+func readConfigFile(configFile string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(configFile)) {
+	case ".toml":
+		err = toml.Unmarshal(content, &config)
+	case ".yaml", ".yml":
+		var yamlConfig map[interface{}]interface{}
+		err = yaml.Unmarshal(content, &yamlConfig)
+		config = stringKeys(yamlConfig)
+	case ".json":
+		err = json.Unmarshal(content, &config)
+	default:
+		return nil, fmt.Errorf("unknown format of the configuration file %s", configFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", configFile, err)
+	}
+	return config, nil
+}
+
+// configValue returns the value of key, the keys of hugo being case insensitive.
+func configValue(config map[string]interface{}, key string) (interface{}, bool) {
+	for name, value := range config {
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func configSection(config map[string]interface{}, key string) map[string]interface{} {
+	value, _ := configValue(config, key)
+	switch section := value.(type) {
+	case map[string]interface{}:
+		return section
+	case map[interface{}]interface{}:
+		return stringKeys(section)
+	}
+	return nil
+}
+
+func stringKeys(section map[interface{}]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(section))
+	for key, value := range section {
+		converted[fmt.Sprint(key)] = value
+	}
+	return converted
+}
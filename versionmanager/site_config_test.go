@@ -0,0 +1,54 @@
+package versionmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSiteFile(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSiteConfigSource(t *testing.T) {
+	assert := assert.New(t)
+	site, err := ioutil.TempDir("", "hugo-site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(site)
+
+	source := NewSiteConfigSource(site, nil)
+	_, found, err := source.Lookup()
+	assert.Nil(err)
+	assert.False(found, "a site without config declares no version")
+
+	writeSiteFile(t, filepath.Join(site, "hugo.toml"), "title = \"site\"\n")
+	writeSiteFile(t, filepath.Join(site, "config", "_default", "module.yaml"), "hugoVersion:\n  min: \"0.72.0\"\n  extended: true\n")
+	spec, found, err := source.Lookup()
+	assert.Nil(err)
+	assert.True(found)
+	assert.Equal(">=0.72.0-extended", spec, "the config directory should be read")
+	assert.Equal("site config "+filepath.Join(site, "config", "_default", "module.yaml"), source.Name())
+
+	writeSiteFile(t, filepath.Join(site, "hugo.toml"), "[module.hugoVersion]\nmin = \"0.72.0\"\nmax = \"0.80.0\"\n")
+	spec, _, err = source.Lookup()
+	assert.Nil(err)
+	assert.Equal(">=0.72.0,<=0.80.0", spec, "the root config file should win")
+
+	writeSiteFile(t, filepath.Join(site, "other.json"), `{"Module": {"HugoVersion": {"Extended": true}}}`)
+	spec, _, err = NewSiteConfigSource(site, []string{"other.json"}).Lookup()
+	assert.Nil(err)
+	assert.Equal("latest-extended", spec, "the files given with --config should replace the root config file")
+
+	_, _, err = NewSiteConfigSource(site, []string{"missing.toml"}).Lookup()
+	assert.NotNil(err)
+}
@@ -0,0 +1,176 @@
+package versionmanager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PinFileName is the name of the file pinning the hugo version of a project.
+const PinFileName = ".hugo-version"
+
+// SpecSource is a place where the desired version spec may be declared.
+type SpecSource interface {
+	Name() string
+	Lookup() (spec string, found bool, err error)
+}
+
+type SourceResult struct {
+	Source string
+	Spec   string
+	Found  bool
+	// Declaration is the text the spec has been translated from, when it differs from the spec.
+	Declaration string
+}
+
+type SpecResolution struct {
+	Spec      string
+	Source    string
+	Consulted []SourceResult
+	// Requirements are the requirements the spec has been combined with by Constrain, the project first.
+	Requirements []Requirement
+	// ExtendedReason explains why RequireExtended selected the extended edition.
+	ExtendedReason string
+}
+
+// ResolveSpec consults the sources in order of precedence until one declares a
+// spec, the sources of lower precedence aren't read.
+func ResolveSpec(sources ...SpecSource) (*SpecResolution, error) {
+	resolution := new(SpecResolution)
+	for _, source := range sources {
+		spec, found, err := source.Lookup()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.Name(), err)
+		}
+		result := SourceResult{Source: source.Name(), Spec: spec, Found: found}
+		if declared, ok := source.(interface{ Declaration() string }); ok && found && declared.Declaration() != spec {
+			result.Declaration = declared.Declaration()
+		}
+		resolution.Consulted = append(resolution.Consulted, result)
+		if found {
+			resolution.Spec = spec
+			resolution.Source = source.Name()
+			return resolution, nil
+		}
+	}
+	return nil, fmt.Errorf("no version spec has been declared")
+}
+
+type valueSource struct {
+	name  string
+	value string
+	isSet bool
+}
+
+// NewValueSource returns a source for an already known value, such as a command line flag.
+func NewValueSource(name string, value string, isSet bool) SpecSource {
+	return &valueSource{name: name, value: value, isSet: isSet}
+}
+
+func (source *valueSource) Name() string {
+	return source.name
+}
+
+func (source *valueSource) Lookup() (string, bool, error) {
+	return source.value, source.isSet, nil
+}
+
+type envSource struct {
+	variable string
+}
+
+func NewEnvSource(variable string) SpecSource {
+	return &envSource{variable: variable}
+}
+
+func (source *envSource) Name() string {
+	return "env " + source.variable
+}
+
+func (source *envSource) Lookup() (string, bool, error) {
+	spec, found := os.LookupEnv(source.variable)
+	spec = strings.TrimSpace(spec)
+	return spec, found && spec != "", nil
+}
+
+// fileSource reads the closest file named fileName found in directory or in
+// one of its parents, parse translating its content into a spec.
+type fileSource struct {
+	fileName    string
+	directory   string
+	path        string
+	declaration string
+	parse       func(content []byte) (spec string, declaration string, err error)
+}
+
+// NewPinFileSource returns a source reading the closest pin file found in
+// directory or in one of its parents.
+func NewPinFileSource(directory string) SpecSource {
+	return &fileSource{fileName: PinFileName, directory: directory, parse: func(content []byte) (string, string, error) {
+		spec := strings.TrimSpace(string(content))
+		return spec, spec, nil
+	}}
+}
+
+func (source *fileSource) Name() string {
+	if source.path != "" {
+		return "file " + source.path
+	}
+	return "file " + source.fileName
+}
+
+func (source *fileSource) Lookup() (string, bool, error) {
+	path, err := findUpward(source.directory, source.fileName)
+	if err != nil || path == "" {
+		return "", false, err
+	}
+	source.path = path
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	// the path is reported by the name of the source
+	spec, declaration, err := source.parse(content)
+	if err != nil {
+		return "", false, err
+	}
+	source.declaration = declaration
+	return spec, spec != "", nil
+}
+
+// Declaration returns the text the spec has been translated from.
+func (source *fileSource) Declaration() string {
+	return source.declaration
+}
+
+// WritePinFile pins spec as the hugo version of the project in directory.
+func WritePinFile(directory string, spec string) (string, error) {
+	path := filepath.Join(directory, PinFileName)
+	return path, ioutil.WriteFile(path, []byte(spec+"\n"), 0644)
+}
+
+// FindPinFile returns the path of the closest pin file, or an empty path if
+// there is none between directory and the root of the filesystem.
+func FindPinFile(directory string) (string, error) {
+	return findUpward(directory, PinFileName)
+}
+
+func findUpward(directory string, fileName string) (string, error) {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(directory, fileName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return "", nil
+		}
+		directory = parent
+	}
+}
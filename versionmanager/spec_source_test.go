@@ -0,0 +1,88 @@
+package versionmanager
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSpec(t *testing.T) {
+	assert := assert.New(t)
+
+	resolution, err := ResolveSpec(
+		NewValueSource("flag", "latest", false),
+		NewValueSource("file", "0.72", true),
+		NewValueSource("default", "latest", true),
+	)
+	assert.Nil(err)
+	assert.Equal("0.72", resolution.Spec)
+	assert.Equal("file", resolution.Source)
+	assert.Len(resolution.Consulted, 2, "the sources following the one declaring the spec shouldn't be consulted")
+
+	root, err := ioutil.TempDir("", "resolve-spec")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	assert.Nil(ioutil.WriteFile(filepath.Join(root, PinFileName), []byte("unused"), 0644))
+	broken := &fileSource{fileName: PinFileName, directory: root, parse: func(content []byte) (string, string, error) {
+		return "", "", errors.New("unreadable")
+	}}
+	resolution, err = ResolveSpec(NewValueSource("flag", "0.72.1", true), broken)
+	assert.Nil(err, "a source following the one declaring the spec shouldn't be read")
+	assert.Equal("0.72.1", resolution.Spec)
+	_, err = ResolveSpec(broken)
+	assert.Equal("file "+filepath.Join(root, PinFileName)+": unreadable", err.Error(), "the path should be reported once")
+
+	_, err = ResolveSpec(NewValueSource("flag", "", false))
+	assert.NotNil(err, "an error should be returned when no source declares a spec")
+}
+
+func TestPinFileSource(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "pin-file")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	nested := filepath.Join(root, "content", "posts")
+	assert.Nil(os.MkdirAll(nested, 0755))
+
+	source := NewPinFileSource(nested)
+	_, found, err := source.Lookup()
+	assert.Nil(err)
+	assert.False(found)
+
+	pinFile := filepath.Join(root, PinFileName)
+	assert.Nil(ioutil.WriteFile(pinFile, []byte("0.72.1-extended\n"), 0644))
+	spec, found, err := source.Lookup()
+	assert.Nil(err)
+	assert.True(found, "the pin file of a parent directory should be found")
+	assert.Equal("0.72.1-extended", spec)
+	assert.Equal("file "+pinFile, source.Name())
+}
+
+func TestConfig(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "config")
+	assert.Nil(err)
+	defer os.RemoveAll(root)
+	path := filepath.Join(root, ConfigFileName)
+
+	config, err := ReadConfig(path)
+	assert.Nil(err, "a missing configuration file should be empty")
+	assert.Equal("", config.DefaultVersion)
+
+	config.DefaultVersion = "0.120-extended"
+	assert.Nil(config.Write(path))
+	config, err = ReadConfig(path)
+	assert.Nil(err)
+	assert.Equal("0.120-extended", config.DefaultVersion)
+
+	pinFile, err := WritePinFile(root, "0.118-extended")
+	assert.Nil(err)
+	spec, found, err := NewPinFileSource(root).Lookup()
+	assert.Nil(err)
+	assert.True(found)
+	assert.Equal("0.118-extended", spec, "the pin file written should be read back from "+pinFile)
+}
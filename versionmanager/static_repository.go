@@ -0,0 +1,347 @@
+package versionmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// MirrorIndexName is the file listing the releases at the root of an http mirror.
+// It can be a dump of the releases listed by the GitHub API, the assets whose
+// download url is missing are looked for at <mirror url>/<tag>/<asset name>.
+const MirrorIndexName = "releases.json"
+
+// staticRepository serves releases listed once, from an index or a directory.
+type staticRepository struct {
+	load     func(ctx context.Context) ([]*staticRelease, error)
+	lock     sync.Mutex
+	loaded   bool
+	releases []*staticRelease
+	err      error
+}
+
+type staticRelease struct {
+	name        string
+	tag         string
+	publishedAt time.Time
+	prerelease  bool
+	draft       bool
+	body        string
+	version     hugoversion.Version
+	assets      []*staticAsset
+}
+
+type staticAsset struct {
+	name        string
+	url         string
+	size        int64
+	contentType string
+	digest      string
+}
+
+func newStaticRepository(load func(ctx context.Context) ([]*staticRelease, error)) *staticRepository {
+	return &staticRepository{load: load}
+}
+
+// list returns the releases which have a version, the latest first. They are
+// loaded again by the next call when ctx was done before the end of the loading.
+func (repo *staticRepository) list(ctx context.Context) ([]*staticRelease, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+	if !repo.loaded {
+		releases, err := repo.load(ctx)
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		repo.loaded, repo.err = true, err
+		for _, release := range releases {
+			version, err := hugoversion.Parse(release.name)
+			if err != nil {
+				continue
+			}
+			release.version = version
+			release.prerelease = release.prerelease || version.Prerelease != ""
+			repo.releases = append(repo.releases, release)
+		}
+		sort.SliceStable(repo.releases, func(i, j int) bool {
+			return repo.releases[j].version.Less(repo.releases[i].version)
+		})
+	}
+	return repo.releases, repo.err
+}
+
+func (repo *staticRepository) GetLatestRelease(ctx context.Context) (Release, error) {
+	releases, err := repo.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if !release.prerelease && !release.draft {
+			return release, nil
+		}
+	}
+	return nil, &VersionNotFoundError{Version: "latest", Reason: "the repository has no release"}
+}
+
+func (repo *staticRepository) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
+	releases, err := repo.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if release.tag == tag || release.name == tag {
+			return release, nil
+		}
+	}
+	return nil, &VersionNotFoundError{Version: tag, Reason: "no release has this tag"}
+}
+
+// GetPreviousRelease returns the highest release lower than tag, the release
+// tagged tag doesn't need to exist.
+func (repo *staticRepository) GetPreviousRelease(ctx context.Context, tag string) (Release, error) {
+	releases, err := repo.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := hugoversion.Parse(tag)
+	if err != nil {
+		return nil, &InvalidVersionSpecError{Spec: tag}
+	}
+	for _, release := range releases {
+		if release.version.Less(version) && !release.prerelease && !release.draft {
+			return release, nil
+		}
+	}
+	return nil, &VersionNotFoundError{Version: tag, Reason: "no previous release found"}
+}
+
+func (repo *staticRepository) ListReleases(ctx context.Context) ([]Release, error) {
+	releases, err := repo.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Release, 0, len(releases))
+	for _, release := range releases {
+		list = append(list, release)
+	}
+	return list, nil
+}
+
+func (release *staticRelease) GetName() string {
+	return release.name
+}
+
+func (release *staticRelease) GetTagName() string {
+	return release.tag
+}
+
+func (release *staticRelease) GetPublishedAt() time.Time {
+	return release.publishedAt
+}
+
+func (release *staticRelease) GetPrerelease() bool {
+	return release.prerelease
+}
+
+func (release *staticRelease) GetDraft() bool {
+	return release.draft
+}
+
+func (release *staticRelease) GetBody() string {
+	return release.body
+}
+
+func (release *staticRelease) GetAssetByName(name string) (Asset, error) {
+	for _, asset := range release.assets {
+		if asset.name == name {
+			return asset, nil
+		}
+	}
+	return nil, &AssetNotFoundError{Asset: name, Release: release.name}
+}
+
+func (release *staticRelease) ListAssets() []Asset {
+	assets := make([]Asset, 0, len(release.assets))
+	for _, asset := range release.assets {
+		assets = append(assets, asset)
+	}
+	return assets
+}
+
+func (asset *staticAsset) GetName() string {
+	return asset.name
+}
+
+func (asset *staticAsset) GetDownloadUrl() string {
+	return asset.url
+}
+
+func (asset *staticAsset) GetSize() int64 {
+	return asset.size
+}
+
+func (asset *staticAsset) GetContentType() string {
+	return asset.contentType
+}
+
+func (asset *staticAsset) GetDigest() string {
+	return asset.digest
+}
+
+type mirrorRelease struct {
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	PublishedAt time.Time `json:"published_at"`
+	Prerelease  bool      `json:"prerelease"`
+	Draft       bool      `json:"draft"`
+	Body        string    `json:"body"`
+	Assets      []struct {
+		Name        string `json:"name"`
+		URL         string `json:"browser_download_url"`
+		Size        *int64 `json:"size"`
+		ContentType string `json:"content_type"`
+		Digest      string `json:"digest"`
+	} `json:"assets"`
+}
+
+func newHTTPMirrorBackend(config BackendConfig) (RepositoryClient, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("the url of the http mirror must be given")
+	}
+	base, err := url.Parse(strings.TrimSuffix(config.URL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid url of the http mirror: %w", err)
+	}
+	return newStaticRepository(func(ctx context.Context) ([]*staticRelease, error) {
+		return loadMirrorIndex(ctx, config.HTTPClient, base)
+	}), nil
+}
+
+func loadMirrorIndex(ctx context.Context, client *http.Client, base *url.URL) ([]*staticRelease, error) {
+	indexURL := base.ResolveReference(&url.URL{Path: MirrorIndexName}).String()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(request)
+	if err != nil {
+		return nil, &OfflineError{Reason: "the mirror is unreachable", Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(indexURL, resp)
+	}
+	index := []mirrorRelease{}
+	if err = json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("invalid index %s: %w", indexURL, err)
+	}
+	releases := []*staticRelease{}
+	for _, item := range index {
+		release := &staticRelease{
+			name:        item.Name,
+			tag:         item.TagName,
+			publishedAt: item.PublishedAt,
+			prerelease:  item.Prerelease,
+			draft:       item.Draft,
+			body:        item.Body,
+		}
+		if release.name == "" {
+			release.name = release.tag
+		}
+		if release.tag == "" {
+			release.tag = release.name
+		}
+		for _, asset := range item.Assets {
+			assetURL := &url.URL{Path: release.tag + "/" + asset.Name}
+			if asset.URL != "" {
+				if assetURL, err = url.Parse(asset.URL); err != nil {
+					return nil, fmt.Errorf("invalid url of the asset %s: %w", asset.Name, err)
+				}
+			}
+			size := int64(-1)
+			if asset.Size != nil {
+				size = *asset.Size
+			}
+			release.assets = append(release.assets, &staticAsset{
+				name:        asset.Name,
+				url:         base.ResolveReference(assetURL).String(),
+				size:        size,
+				contentType: asset.ContentType,
+				digest:      asset.Digest,
+			})
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+// newFileBackend serves the releases of a directory holding a sub directory
+// per release, named after its tag and containing its assets. The publication
+// dates and notes of the releases are read from an optional releases.json,
+// the releases it doesn't list having no date.
+func newFileBackend(config BackendConfig) (RepositoryClient, error) {
+	if config.Directory == "" {
+		return nil, fmt.Errorf("the directory of the file backend must be given")
+	}
+	directory, err := filepath.Abs(config.Directory)
+	if err != nil {
+		return nil, err
+	}
+	return newStaticRepository(func(ctx context.Context) ([]*staticRelease, error) {
+		return loadReleaseDirectory(directory)
+	}), nil
+}
+
+func loadReleaseDirectory(directory string) ([]*staticRelease, error) {
+	entries, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	// the modification times of a copied directory don't tell when the releases
+	// have been published, only an index in the format of the http mirror does
+	published := map[string]mirrorRelease{}
+	if content, err := ioutil.ReadFile(filepath.Join(directory, MirrorIndexName)); err == nil {
+		index := []mirrorRelease{}
+		if err = json.Unmarshal(content, &index); err != nil {
+			return nil, fmt.Errorf("invalid index %s: %w", filepath.Join(directory, MirrorIndexName), err)
+		}
+		for _, item := range index {
+			published[item.TagName] = item
+		}
+	}
+	releases := []*staticRelease{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		metadata := published[entry.Name()]
+		release := &staticRelease{name: entry.Name(), tag: entry.Name(), publishedAt: metadata.PublishedAt, body: metadata.Body}
+		assets, err := ioutil.ReadDir(filepath.Join(directory, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			if !asset.IsDir() {
+				release.assets = append(release.assets, &staticAsset{
+					name:        asset.Name(),
+					url:         fileURL(filepath.Join(directory, entry.Name(), asset.Name())),
+					size:        asset.Size(),
+					contentType: mime.TypeByExtension(filepath.Ext(asset.Name())),
+				})
+			}
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
@@ -0,0 +1,155 @@
+package versionmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+)
+
+// PinUpdate is the new content of a file pinning the hugo version.
+type PinUpdate struct {
+	Path   string
+	Before string
+	After  string
+}
+
+// pinPatterns locate the versions in the files pinning hugo, the version
+// being the second group. The editions are left as they are.
+var pinPatterns = map[string]*regexp.Regexp{
+	PinFileName:          regexp.MustCompile(`^(\s*)(v?\d+(?:\.\d+){0,2})`),
+	ToolVersionsFileName: regexp.MustCompile(`(?m)^(\s*hugo(?:-extended)?\s+(?:extended_(?:withdeploy_)?)?)(v?\d+(?:\.\d+){0,2})`),
+	PackageJSONFileName:  regexp.MustCompile(`("hugo-bin"\s*:\s*\{[^}]*?"version"\s*:\s*")(v?\d+(?:\.\d+){0,2})`),
+	NetlifyFileName:      regexp.MustCompile(`(\bHUGO_VERSION\s*=\s*["'])(v?\d+(?:\.\d+){0,2})`),
+}
+
+// workflowPattern locates the hugo-version input of the setup actions, and the HUGO_VERSION variable, of the GitHub workflows.
+var workflowPattern = regexp.MustCompile(`(?m)(\b(?:hugo-version|HUGO_VERSION):\s*['"]?)(v?\d+(?:\.\d+){0,2})`)
+
+// PlanUpgrade returns the changes pinning version in the files found from
+// directory up to the root of its repository: the closest .hugo-version,
+// .tool-versions, package.json and netlify.toml, and the closest GitHub
+// workflows. Outside of a repository, only the files of directory are changed,
+// never the ones of its parents such as the home directory. The formatting of
+// the files is kept, the versions only are replaced.
+func PlanUpgrade(directory string, version hugoversion.Version) ([]PinUpdate, error) {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, err
+	}
+	root := repositoryRoot(directory)
+	files := map[string]*regexp.Regexp{}
+	for _, fileName := range []string{PinFileName, ToolVersionsFileName, PackageJSONFileName, NetlifyFileName} {
+		path, err := findUpwardWithin(directory, root, fileName)
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			files[path] = pinPatterns[fileName]
+		}
+	}
+	workflows, err := workflowFiles(directory, root)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range workflows {
+		files[path] = workflowPattern
+	}
+
+	updates := []PinUpdate{}
+	for path, pattern := range files {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		before := string(content)
+		after := pattern.ReplaceAllStringFunc(before, func(match string) string {
+			groups := pattern.FindStringSubmatch(match)
+			return groups[1] + pinnedVersion(groups[2], version)
+		})
+		if after != before {
+			updates = append(updates, PinUpdate{Path: path, Before: before, After: after})
+		}
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Path < updates[j].Path })
+	return updates, nil
+}
+
+// Apply writes the new content of the file.
+func (update PinUpdate) Apply() error {
+	info, err := os.Stat(update.Path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(update.Path, []byte(update.After), info.Mode())
+}
+
+// pinnedVersion writes version like the version it replaces, with or without its v prefix.
+func pinnedVersion(replaced string, version hugoversion.Version) string {
+	text := assetVersion(&coreVersion{major: version.Major, minor: version.Minor, patch: version.Patch})
+	if strings.HasPrefix(replaced, "v") {
+		return "v" + text
+	}
+	return text
+}
+
+// workflowFiles lists the workflows of the closest .github/workflows directory, up to root.
+func workflowFiles(directory string, root string) ([]string, error) {
+	for {
+		workflows := filepath.Join(directory, ".github", "workflows")
+		if info, err := os.Stat(workflows); err == nil && info.IsDir() {
+			files := []string{}
+			for _, pattern := range []string{"*.yml", "*.yaml"} {
+				matches, err := filepath.Glob(filepath.Join(workflows, pattern))
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, matches...)
+			}
+			return files, nil
+		}
+		parent := filepath.Dir(directory)
+		if directory == root || parent == directory {
+			return nil, nil
+		}
+		directory = parent
+	}
+}
+
+// findUpwardWithin returns the closest file named fileName in the absolute
+// directory or its parents up to root, or an empty path when none is found.
+func findUpwardWithin(directory string, root string, fileName string) (string, error) {
+	for {
+		candidate := filepath.Join(directory, fileName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(directory)
+		if directory == root || parent == directory {
+			return "", nil
+		}
+		directory = parent
+	}
+}
+
+// repositoryRoot returns the closest of the absolute directory and its parents
+// holding a .git or a .github/workflows directory, or directory itself when none does.
+func repositoryRoot(directory string) string {
+	for candidate := directory; ; {
+		if _, err := os.Stat(filepath.Join(candidate, ".git")); err == nil {
+			return candidate
+		}
+		if info, err := os.Stat(filepath.Join(candidate, ".github", "workflows")); err == nil && info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(candidate)
+		if parent == candidate {
+			return directory
+		}
+		candidate = parent
+	}
+}
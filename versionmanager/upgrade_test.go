@@ -0,0 +1,63 @@
+package versionmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	assert := assert.New(t)
+	home, err := ioutil.TempDir("", "hugo-upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	writeSiteFile(t, filepath.Join(home, ToolVersionsFileName), "hugo 0.112.0\n")
+	repository := filepath.Join(home, "repository")
+	writeSiteFile(t, filepath.Join(repository, ".git", "HEAD"), "ref: refs/heads/main\n")
+	site := filepath.Join(repository, "site")
+	writeSiteFile(t, filepath.Join(site, PinFileName), "0.112.0-extended\n")
+	writeSiteFile(t, filepath.Join(site, ToolVersionsFileName), "nodejs 20.1.0\nhugo extended_0.112.0 # pinned\n")
+	writeSiteFile(t, filepath.Join(site, PackageJSONFileName), "{\n  \"version\": \"1.0.0\",\n  \"hugo-bin\": {\n    \"buildTags\": \"extended\",\n    \"version\": \"0.112.0\"\n  }\n}\n")
+	writeSiteFile(t, filepath.Join(repository, NetlifyFileName), "[build.environment]\n  HUGO_VERSION = \"0.112.0\"\n")
+	writeSiteFile(t, filepath.Join(repository, ".github", "workflows", "pages.yml"), "steps:\n  - uses: peaceiris/actions-hugo@v2\n    with:\n      hugo-version: '0.112.0'\n      extended: true\n")
+	writeSiteFile(t, filepath.Join(repository, ".github", "workflows", "lint.yml"), "steps:\n  - run: make lint\n")
+
+	updates, err := PlanUpgrade(site, hugoversion.MustParse("v0.121.1"))
+	assert.Nil(err)
+	after := map[string]string{}
+	for _, update := range updates {
+		after[update.Path] = update.After
+	}
+	assert.Equal(map[string]string{
+		filepath.Join(site, PinFileName):                               "0.121.1-extended\n",
+		filepath.Join(site, ToolVersionsFileName):                      "nodejs 20.1.0\nhugo extended_0.121.1 # pinned\n",
+		filepath.Join(site, PackageJSONFileName):                       "{\n  \"version\": \"1.0.0\",\n  \"hugo-bin\": {\n    \"buildTags\": \"extended\",\n    \"version\": \"0.121.1\"\n  }\n}\n",
+		filepath.Join(repository, NetlifyFileName):                     "[build.environment]\n  HUGO_VERSION = \"0.121.1\"\n",
+		filepath.Join(repository, ".github", "workflows", "pages.yml"): "steps:\n  - uses: peaceiris/actions-hugo@v2\n    with:\n      hugo-version: '0.121.1'\n      extended: true\n",
+	}, after, "the version of the package itself and the files without hugo version should be left alone")
+
+	assert.Nil(updates[0].Apply())
+	updates, err = PlanUpgrade(site, hugoversion.MustParse("v0.121.1"))
+	assert.Nil(err)
+	assert.Len(updates, 4, "the applied update shouldn't be planned again")
+
+	assert.Nil(os.RemoveAll(filepath.Join(site, ToolVersionsFileName)))
+	updates, err = PlanUpgrade(site, hugoversion.MustParse("v0.121.1"))
+	assert.Nil(err)
+	for _, update := range updates {
+		assert.NotEqual(filepath.Join(home, ToolVersionsFileName), update.Path, "the files above the repository should be left alone")
+	}
+
+	outside := filepath.Join(home, "outside")
+	writeSiteFile(t, filepath.Join(outside, NetlifyFileName), "[build.environment]\n  HUGO_VERSION = \"0.112.0\"\n")
+	updates, err = PlanUpgrade(outside, hugoversion.MustParse("v0.121.1"))
+	assert.Nil(err)
+	assert.Len(updates, 1, "outside of a repository, only the files of the directory should be changed")
+	assert.Equal(filepath.Join(outside, NetlifyFileName), updates[0].Path)
+}
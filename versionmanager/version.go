@@ -1,17 +1,28 @@
 package versionmanager
 
 import (
-	"fmt"
+	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
 	osFile "os"
-	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/pkg/errors"
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
 )
 
+// LatestStable selects the newest release published for at least the minimum
+// release age, see WithMinReleaseAge.
+const LatestStable = "latest-stable"
+
+// CommitDate selects the release that was current at the date of the HEAD
+// commit of the site, see SpecResolution.ResolveCommitDate.
+const CommitDate = "@commit"
+
+const releaseDateLayout = "2006-01-02"
+
 type versionPrecision int
 
 const (
@@ -28,26 +39,58 @@ type coreVersion struct {
 
 type Version struct {
 	*coreVersion
-	extended bool
-	finder   assetFinder
+	extended   bool
+	withDeploy bool
+	finder     assetFinder
+	observer   Observer
 }
 
 func NewVersion(desiredVersion string) (*Version, error) {
 	finder := newAssetFinder()
-	return newVersion(finder, desiredVersion)
+	return newVersion(context.Background(), finder, desiredVersion)
 }
 
-func newVersion(finder assetFinder, desiredVersion string) (selectedVersion *Version, err error) {
+func newVersion(ctx context.Context, finder assetFinder, desiredVersion string) (selectedVersion *Version, err error) {
 	selectedVersion = new(Version)
 	selectedVersion.finder = finder
 
-	desiredVersion, isExtended, err := extractExtension(desiredVersion)
-	selectedVersion.extended = isExtended
+	desiredVersion, edition, err := extractEdition(desiredVersion)
+	if err != nil {
+		return nil, err
+	}
+	selectedVersion.setEdition(edition)
 
 	if desiredVersion == "latest" {
-		selectedVersion.coreVersion, err = finder.findLatestVersion()
-		fmt.Println(desiredVersion)
+		selectedVersion.coreVersion, err = finder.findLatestVersion(ctx)
+
+		return selectedVersion, err
+	}
+
+	desiredVersion, constraint, err := splitConstraint(desiredVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if desiredVersion == LatestStable {
+		selectedVersion.coreVersion, err = finder.resolveStable(ctx, constraint)
+		return selectedVersion, err
+	}
+
+	if strings.HasPrefix(desiredVersion, "@") {
+		date, err := parseReleaseDate(desiredVersion)
+		if err != nil {
+			return nil, err
+		}
+		selectedVersion.coreVersion, err = finder.resolveDate(ctx, date, constraint)
+		return selectedVersion, err
+	}
 
+	if hugoversion.IsConstraint(desiredVersion) {
+		constraint, err := hugoversion.ParseConstraint(desiredVersion)
+		if err != nil {
+			return nil, &InvalidVersionSpecError{Spec: desiredVersion}
+		}
+		selectedVersion.coreVersion, err = finder.resolveConstraint(ctx, constraint)
 		return selectedVersion, err
 	}
 
@@ -56,11 +99,14 @@ func newVersion(finder assetFinder, desiredVersion string) (selectedVersion *Ver
 		return nil, err
 	}
 
-	selectedVersion.coreVersion, err = finder.resolveVersion(coreVersion, precision)
+	selectedVersion.coreVersion, err = finder.resolveVersion(ctx, coreVersion, precision)
 	return
 }
 
 func parseCoreVersion(version string) (*coreVersion, versionPrecision, error) {
+	if version == "" {
+		return nil, -1, &InvalidVersionSpecError{Spec: version}
+	}
 	if version[0] == 'v' {
 		version = version[1:]
 	}
@@ -77,116 +123,220 @@ func parseCoreVersion(version string) (*coreVersion, versionPrecision, error) {
 func extractCoreIdentifiers(version string) (coreVer *coreVersion, precision versionPrecision, err error) {
 	splitVersion := strings.Split(version, ".")
 	if len(splitVersion) > 3 {
-		return nil, -1, errors.New("the version must be in form of latest[-extended] or [v]int[.int[.int]][-extended]")
+		return nil, -1, &InvalidVersionSpecError{Spec: version}
 	}
 	precision = versionPrecision(len(splitVersion))
+	identifiers := make([]int, len(splitVersion))
+	for i, identifier := range splitVersion {
+		if identifiers[i], err = strconv.Atoi(identifier); err != nil || identifiers[i] < 0 {
+			return nil, -1, &InvalidVersionSpecError{Spec: version}
+		}
+	}
 	coreVer = new(coreVersion)
 	switch precision {
 	case patch:
-		coreVer.patch, err = strconv.Atoi(splitVersion[2])
+		coreVer.patch = identifiers[2]
 		fallthrough
 	case minor:
-		coreVer.minor, err = strconv.Atoi(splitVersion[1])
+		coreVer.minor = identifiers[1]
 		fallthrough
 	case major:
-		coreVer.major, err = strconv.Atoi(splitVersion[0])
-	default:
-		err = errors.New("the version must be in form of latest[-extended] or [v]int[.int[.int]][-extended]")
+		coreVer.major = identifiers[0]
 	}
 	return
 }
 
 func (version *Version) String() string {
-	fmt.Printf("%+v", version.major)
-	versionString := fmt.Sprintf("v%d.%d.%d", version.major, version.minor, version.patch)
-	if version.extended {
-		versionString += "-extended"
+	return version.Value().String()
+}
+
+// ReleaseNotesURL returns the page of the release on GitHub, with its release notes.
+func (version *Version) ReleaseNotesURL() string {
+	return releaseNotesURL(releaseTag(version.coreVersion))
+}
+
+func releaseNotesURL(tag string) string {
+	return "https://github.com/gohugoio/hugo/releases/tag/" + tag
+}
+
+// Value returns the plain version, detached from the repository it has been resolved with.
+func (version *Version) Value() hugoversion.Version {
+	return hugoversion.Version{Major: version.major, Minor: version.minor, Patch: version.patch, Edition: version.edition()}
+}
+
+func (version *Version) edition() hugoversion.Edition {
+	switch {
+	case version.withDeploy:
+		return hugoversion.ExtendedWithDeploy
+	case version.extended:
+		return hugoversion.Extended
 	}
-	return versionString
+	return hugoversion.Standard
 }
 
-func extractExtension(version string) (versionCore string, isExtented bool, err error) {
-	splitVersion := strings.Split(version, "-")
-	if len(splitVersion) > 2 {
-		return "", false, errors.New("the version must be in form of latest[-extended] or [v]int[.int[.int]][-extended]")
+func (version *Version) setEdition(edition hugoversion.Edition) {
+	version.extended = edition != hugoversion.Standard
+	version.withDeploy = edition == hugoversion.ExtendedWithDeploy
+}
+
+// extractEdition splits a spec such as 0.72-extended into its version and its edition.
+func extractEdition(spec string) (versionCore string, edition hugoversion.Edition, err error) {
+	alias := ""
+	if spec == LatestStable || strings.HasPrefix(spec, LatestStable+"-") || strings.HasPrefix(spec, LatestStable+",") {
+		alias = LatestStable
+	} else if strings.HasPrefix(spec, "@") {
+		// the dashes of a date aren't separating the edition
+		end := len("@") + len(releaseDateLayout)
+		if strings.HasPrefix(spec, CommitDate) {
+			end = len(CommitDate)
+		}
+		if len(spec) < end {
+			return "", hugoversion.Standard, &InvalidVersionSpecError{Spec: spec}
+		}
+		alias = spec[:end]
 	}
+	if alias != "" {
+		rest := spec[len(alias):]
+		if strings.HasPrefix(rest, ",") {
+			constraint, edition, err := extractEdition(rest[1:])
+			if err != nil || !hugoversion.IsConstraint(constraint) {
+				return "", hugoversion.Standard, &InvalidVersionSpecError{Spec: spec}
+			}
+			return alias + "," + constraint, edition, nil
+		}
+		if _, edition, err = extractEdition("latest" + rest); err != nil {
+			return "", hugoversion.Standard, &InvalidVersionSpecError{Spec: spec}
+		}
+		return alias, edition, nil
+	}
+	splitVersion := strings.SplitN(spec, "-", 2)
 	if len(splitVersion) == 1 {
-		return splitVersion[0], false, nil
+		return splitVersion[0], hugoversion.Standard, nil
 	}
-	if splitVersion[1] != "extended" {
-		return "", false, errors.New("the version must be in form of latest[-extended] or [v]int[.int[.int]][-extended]")
+	edition, err = hugoversion.ParseEdition(splitVersion[1])
+	if err != nil || splitVersion[1] == "" {
+		return "", hugoversion.Standard, &InvalidVersionSpecError{Spec: spec}
 	}
-	return splitVersion[0], true, nil
+	return splitVersion[0], edition, nil
 }
 
-func (version *coreVersion) Higher(other *coreVersion, precision versionPrecision) bool {
-	switch precision {
-	case patch:
-		if version.major == other.major && version.minor == other.minor {
-			return (version.patch - other.patch) > 0
-		}
-		fallthrough
-	case minor:
-		if version.major == other.major {
-			return (version.minor - other.minor) > 0
-		}
-		fallthrough
-	case major:
-		return (version.major - other.major) > 0
-	default:
-		panicError := fmt.Sprintf("fatal the comparatorKey: %d doesn't exist", precision)
-		panic(panicError)
+// splitConstraint splits an alias combined by Constrain with the requirements
+// of the site, such as latest-stable,>=0.100.0 or @2023-06-01,>=0.110.0, into
+// the alias and the constraint.
+func splitConstraint(desiredVersion string) (string, hugoversion.Constraint, error) {
+	index := strings.Index(desiredVersion, ",")
+	if index < 0 || !(strings.HasPrefix(desiredVersion, LatestStable+",") || strings.HasPrefix(desiredVersion, "@")) {
+		return desiredVersion, nil, nil
+	}
+	constraint, err := hugoversion.ParseConstraint(desiredVersion[index+1:])
+	if err != nil {
+		return "", nil, &InvalidVersionSpecError{Spec: desiredVersion}
 	}
+	return desiredVersion[:index], constraint, nil
+}
+
+// parseReleaseDate reads a spec such as @2023-06-01, @commit must have been
+// replaced by the date of the commit beforehand.
+func parseReleaseDate(spec string) (time.Time, error) {
+	if spec == CommitDate {
+		return time.Time{}, &InvalidVersionSpecError{Spec: spec}
+	}
+	date, err := time.Parse(releaseDateLayout, strings.TrimPrefix(spec, "@"))
+	if err != nil {
+		return time.Time{}, &InvalidVersionSpecError{Spec: spec}
+	}
+	return date, nil
+}
+
+func (version *coreVersion) Higher(other *coreVersion, precision versionPrecision) bool {
+	return version.compare(other, precision) > 0
 }
 
 func (version *coreVersion) Equal(other *coreVersion, precision versionPrecision) bool {
-	switch precision {
-	case patch:
-		if version.major == other.major && version.minor == other.minor {
-			return (version.patch - other.patch) == 0
+	return version.compare(other, precision) == 0
+}
+
+// compare orders the versions on their identifiers up to precision, a
+// precision out of range is brought back to the closest one.
+func (version *coreVersion) compare(other *coreVersion, precision versionPrecision) int {
+	if precision < major {
+		precision = major
+	}
+	if precision > patch {
+		precision = patch
+	}
+	differences := []int{version.major - other.major, version.minor - other.minor, version.patch - other.patch}
+	for _, difference := range differences[:precision] {
+		if difference > 0 {
+			return 1
 		}
-		return false
-	case minor:
-		if version.major == other.major {
-			return (version.minor - other.minor) == 0
+		if difference < 0 {
+			return -1
 		}
-		return false
-	case major:
-		return (version.major - other.major) == 0
-	default:
-		panicError := fmt.Sprintf("fatal the comparatorKey: %d doesn't exist", precision)
-		panic(panicError)
 	}
+	return 0
 }
 
-func getTemporaryArchiveName() string {
-	extension := ".tar.gz"
-	if runtime.GOOS == "windows" {
-		extension = ".zip"
-	}
-	return fmt.Sprintf("hugoArchive*.%s", extension)
+// AssetName returns the name of the release asset of this version for the current platform.
+func (version *Version) AssetName() (string, error) {
+	return version.finder.assetName(version)
+}
+
+// AssetURL returns the download url of the release asset, it fails when the
+// release doesn't provide an asset for the current platform.
+func (version *Version) AssetURL() (string, error) {
+	return version.finder.findAssetURL(context.Background(), version)
 }
 
 func (version *Version) GetAsset() (f *osFile.File, err error) {
-	url, err := version.finder.findAssetURL(version)
+	return version.download(context.Background(), http.DefaultClient)
+}
+
+// download writes the release asset in a temporary file, reporting the progress to the observer.
+func (version *Version) download(ctx context.Context, client *http.Client) (*osFile.File, error) {
+	asset, err := version.finder.findAsset(ctx, version)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.Get(url)
+	assetName, err := version.AssetName()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	tmpfile, err := ioutil.TempFile("", getTemporaryArchiveName())
+	version.notify(Event{Kind: DownloadStarted, Version: version.String(), Asset: assetName})
+	body, size, err := openURL(ctx, client, asset.GetDownloadUrl())
 	if err != nil {
 		return nil, err
 	}
-	content, err := ioutil.ReadAll(resp.Body)
+	defer body.Close()
+	if size < 0 {
+		size = asset.GetSize()
+	}
+	// the asset name is kept as suffix, the archive format is deduced from it
+	tmpfile, err := ioutil.TempFile("", "*_"+assetName)
 	if err != nil {
 		return nil, err
 	}
-	if _, err := tmpfile.Write(content); err != nil {
+	progress := &progressReader{
+		Reader:   body,
+		observer: version.eventObserver(),
+		event:    Event{Kind: DownloadProgress, Version: version.String(), Asset: assetName, Total: size},
+	}
+	if _, err := io.Copy(tmpfile, progress); err != nil {
+		tmpfile.Close()
+		osFile.Remove(tmpfile.Name())
 		return nil, err
 	}
+	version.notify(Event{Kind: DownloadFinished, Version: version.String(), Asset: assetName, Bytes: progress.event.Bytes, Total: size})
 	return tmpfile, nil
 }
+
+func (version *Version) eventObserver() Observer {
+	if version.observer == nil {
+		return nopObserver{}
+	}
+	return version.observer
+}
+
+func (version *Version) notify(event Event) {
+	version.eventObserver().OnEvent(event)
+}
@@ -1,63 +1,478 @@
 package versionmanager
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/exec"
 	"path"
-	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/TiboStev/hugo-wrapper/hugoversion"
 	archiver "github.com/mholt/archiver/v3"
-	"github.com/pkg/errors"
 )
 
-type VersionManager struct {
+// DefaultMinReleaseAge is the age of the releases selected by latest-stable.
+const DefaultMinReleaseAge = 7 * 24 * time.Hour
+
+// DefaultCacheTTL is the time the cached release notes and release checks are used.
+const DefaultCacheTTL = 24 * time.Hour
+
+// Manager resolves, installs and runs hugo versions. A manager holds no
+// global state, several of them can be used side by side.
+type Manager struct {
 	installDirectory string
+	cacheDir         string
+	repository       RepositoryClient
+	backends         []BackendConfig
+	httpClient       *http.Client
+	logger           Logger
+	goos             string
+	goarch           string
+	cachePolicy      CachePolicy
+	minReleaseAge    time.Duration
+	previousMinor    bool
+	cacheTTL         time.Duration
+	keepVersions     int
+	used             map[string]bool
+	usedLock         sync.Mutex
+	observer         Observer
+	stdin            io.Reader
+	stdout           io.Writer
+	stderr           io.Writer
+}
+
+type Installation struct {
+	Version  string
+	ExecPath string
 }
+
 type HugoInstaller struct {
 	installDirectory string
 	selectedVersion  *Version
 	execPath         string
 }
 
-func NewVersionManager(installDirectory string) (*VersionManager, error) {
-	if _, err := os.Stat(installDirectory); err != nil {
+// New returns a manager installing the versions in the directory given by
+// WithInstallDirectory, by default the releases of gohugoio/hugo on GitHub are used.
+func New(options ...Option) (*Manager, error) {
+	manager := &Manager{
+		logger:        discardLogger{},
+		goos:          goOS(),
+		goarch:        goArch(),
+		cachePolicy:   CachePreferLocal,
+		minReleaseAge: DefaultMinReleaseAge,
+		cacheTTL:      DefaultCacheTTL,
+		observer:      nopObserver{},
+		stdin:         os.Stdin,
+		stdout:        os.Stdout,
+		stderr:        os.Stderr,
+	}
+	for _, option := range options {
+		if err := option(manager); err != nil {
+			return nil, err
+		}
+	}
+	if manager.installDirectory == "" {
+		return nil, errors.New("The installation directory must be given")
+	}
+	if _, err := os.Stat(manager.installDirectory); err != nil {
 		return nil, errors.New("The installation directory doesn't exist")
 	}
-	return &VersionManager{installDirectory: installDirectory}, nil
+	if manager.cacheDir != "" {
+		if err := os.MkdirAll(manager.cacheDir, 0770); err != nil {
+			return nil, fmt.Errorf("the cache directory can't be created: %w", err)
+		}
+	}
+	if manager.repository == nil {
+		repository, err := manager.newRepository()
+		if err != nil {
+			return nil, err
+		}
+		manager.repository = repository
+	}
+	return manager, nil
+}
+
+func (manager *Manager) newRepository() (RepositoryClient, error) {
+	configs := manager.backends
+	if len(configs) == 0 {
+		configs = []BackendConfig{{Type: "github"}}
+	}
+	clients := make([]RepositoryClient, 0, len(configs))
+	for _, config := range configs {
+		if config.HTTPClient == nil {
+			config.HTTPClient = manager.httpClient
+		}
+		client, err := NewBackend(config)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return NewFallbackRepository(clients...), nil
+}
+
+// NewVersionManager returns a manager installing the versions in installDirectory
+// and writing its messages to the standard output.
+func NewVersionManager(installDirectory string) (*Manager, error) {
+	return New(WithInstallDirectory(installDirectory), WithOutput(os.Stdout))
 }
 
-func (manager *VersionManager) GetExecPath(desiredVersion string) (execPath string, version string, err error) {
-	selectedVersion, err := NewVersion(desiredVersion)
+// Explanation details how a version spec has been turned into an executable.
+type Explanation struct {
+	Version     string
+	Candidates  []string
+	AssetName   string
+	ExecPath    string
+	Installed   bool
+	RemoteCalls int
+}
+
+// Resolve selects the version matching spec, in the form of
+// latest[-extended], latest-stable[-extended], [v]major[.minor[.patch]][-extended]
+// or a constraint such as >=0.72,<0.80[-extended].
+func (manager *Manager) Resolve(ctx context.Context, spec string) (*Version, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	manager.logger.Debug("resolving version", "spec", spec)
+	manager.observer.OnEvent(Event{Kind: ResolutionStarted, Spec: spec})
+	var version *Version
+	var err error
+	if manager.cachePolicy == CacheOffline {
+		version, err = manager.resolveInstalled(spec)
+	} else {
+		version, err = newVersion(ctx, manager.newFinder(), spec)
+	}
 	if err != nil {
-		return
+		return nil, err
 	}
-	fmt.Printf("%+v", selectedVersion)
-	version = selectedVersion.String()
-	execPath = path.Join(manager.installDirectory, selectedVersion.String(), binaryName())
-	if isAlreadyInstalled(execPath) {
-		fmt.Println("found local installation")
-		return
+	version.observer = manager.observer
+	manager.observer.OnEvent(Event{Kind: ResolutionFinished, Spec: spec, Version: version.String()})
+	return version, nil
+}
+
+// Install installs version unless it is already installed, and returns the path of its executable.
+func (manager *Manager) Install(ctx context.Context, version *Version) (execPath string, err error) {
+	execPath = manager.execPath(version)
+	manager.markUsed(execPath)
+	if manager.cachePolicy != CacheRefresh && isAlreadyInstalled(execPath) {
+		manager.observer.OnEvent(Event{Kind: CacheHit, Version: version.String(), Path: execPath})
+		return execPath, nil
+	}
+	if manager.cachePolicy == CacheOffline {
+		return "", &OfflineError{Reason: fmt.Sprintf("%s is not installed and the network can't be used", version)}
+	}
+	manager.observer.OnEvent(Event{Kind: CacheMiss, Version: version.String(), Path: execPath})
+	if err = manager.install(ctx, execPath, version); err != nil {
+		return "", err
+	}
+	manager.observer.OnEvent(Event{Kind: ExtractionFinished, Version: version.String(), Path: execPath})
+	return execPath, nil
+}
+
+func (manager *Manager) markUsed(execPath string) {
+	manager.usedLock.Lock()
+	defer manager.usedLock.Unlock()
+	if manager.used == nil {
+		manager.used = map[string]bool{}
 	}
-	fmt.Println("local installation not found")
-	fmt.Println("installation started ...")
-	if err = manager.install(execPath, selectedVersion); err != nil {
+	manager.used[execPath] = true
+}
+
+// ApplyRetention removes the installed versions beyond the keepVersions
+// highest ones, once the command using the manager has finished. The versions
+// installed or run by the manager are always kept, as are the highest installed
+// versions matching the protected specs, such as the one pinned by the project.
+func (manager *Manager) ApplyRetention(protected ...string) error {
+	if manager.keepVersions == 0 {
+		return nil
+	}
+	kept := map[string]bool{}
+	manager.usedLock.Lock()
+	for execPath := range manager.used {
+		kept[execPath] = true
+	}
+	manager.usedLock.Unlock()
+	for _, spec := range protected {
+		if version, err := manager.resolveInstalled(spec); err == nil {
+			kept[manager.execPath(version)] = true
+		}
+	}
+	installations, err := manager.Installations()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(installations, func(i, j int) bool {
+		first, firstErr := hugoversion.Parse(installations[i].Version)
+		second, secondErr := hugoversion.Parse(installations[j].Version)
+		if firstErr != nil || secondErr != nil {
+			return firstErr == nil
+		}
+		return first.Compare(second) > 0
+	})
+	remaining := manager.keepVersions
+	for _, installation := range installations {
+		if kept[installation.ExecPath] {
+			remaining--
+		}
+	}
+	for _, installation := range installations {
+		if kept[installation.ExecPath] {
+			continue
+		}
+		if remaining > 0 {
+			remaining--
+			continue
+		}
+		manager.logger.Debug("removing a version beyond the retention", "version", installation.Version)
+		if err := os.RemoveAll(path.Dir(installation.ExecPath)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Command installs version if needed and returns the command running it with args.
+func (manager *Manager) Command(ctx context.Context, version *Version, args []string) (*exec.Cmd, error) {
+	execPath, err := manager.Install(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	command := exec.CommandContext(ctx, execPath, args...)
+	command.Args[0] = "hugo"
+	command.Stdin = manager.stdin
+	command.Stdout = manager.stdout
+	command.Stderr = manager.stderr
+	return command, nil
+}
+
+// Run installs version if needed and runs it with args, until it exits or ctx is done.
+func (manager *Manager) Run(ctx context.Context, version *Version, args []string) error {
+	command, err := manager.Command(ctx, version, args)
+	if err != nil {
+		return err
+	}
+	manager.logger.Debug("running hugo", "path", command.Path, "args", args)
+	manager.observer.OnEvent(Event{Kind: ExecutionStarted, Version: version.String(), Path: command.Path, Args: args})
+	return command.Run()
+}
+
+// Repository returns the client of the repository the releases are fetched from.
+func (manager *Manager) Repository() RepositoryClient {
+	return manager.repository
+}
+
+// Backends returns the clients of the backends, in order of priority.
+func (manager *Manager) Backends() []RepositoryClient {
+	return backendsOf(manager.repository)
+}
+
+func (manager *Manager) GetExecPath(desiredVersion string) (execPath string, version string, err error) {
+	ctx := context.Background()
+	selectedVersion, err := manager.Resolve(ctx, desiredVersion)
+	if err != nil {
 		return
 	}
-	fmt.Println("installation ended")
+	version = selectedVersion.String()
+	execPath, err = manager.Install(ctx, selectedVersion)
 	return
 }
 
-func (manager *VersionManager) install(execPath string, version *Version) (err error) {
-	fmt.Println("download started")
-	assetTmpFile, err := version.GetAsset()
-	fmt.Println("download ended")
-	defer assetTmpFile.Close()
+// Which returns the path of the executable GetExecPath would return, without installing it.
+func (manager *Manager) Which(desiredVersion string) (execPath string, installed bool, err error) {
+	selectedVersion, err := manager.Resolve(context.Background(), desiredVersion)
+	if err != nil {
+		return "", false, err
+	}
+	execPath = manager.execPath(selectedVersion)
+	return execPath, isAlreadyInstalled(execPath), nil
+}
+
+// Explain resolves the desired version like GetExecPath does, without
+// installing it, and reports the steps of the resolution.
+func (manager *Manager) Explain(desiredVersion string) (*Explanation, error) {
+	selectedVersion, err := manager.Resolve(context.Background(), desiredVersion)
+	if err != nil {
+		return nil, err
+	}
+	assetName, err := selectedVersion.AssetName()
+	if err != nil {
+		return nil, err
+	}
+	explanation := &Explanation{
+		Version:     selectedVersion.String(),
+		Candidates:  selectedVersion.finder.consideredReleases(),
+		AssetName:   assetName,
+		ExecPath:    manager.execPath(selectedVersion),
+		RemoteCalls: selectedVersion.finder.remoteCallCount(),
+	}
+	explanation.Installed = isAlreadyInstalled(explanation.ExecPath)
+	return explanation, nil
+}
+
+func (manager *Manager) InstallDirectory() string {
+	return manager.installDirectory
+}
+
+// cacheDirectory returns the directory of the cached release notes and release checks.
+func (manager *Manager) cacheDirectory() string {
+	if manager.cacheDir == "" {
+		return manager.installDirectory
+	}
+	return manager.cacheDir
+}
+
+// Installations lists the versions already installed.
+func (manager *Manager) Installations() ([]Installation, error) {
+	entries, err := ioutil.ReadDir(manager.installDirectory)
+	if err != nil {
+		return nil, err
+	}
+	installations := []Installation{}
+	for _, entry := range entries {
+		execPath := path.Join(manager.installDirectory, entry.Name(), binaryName(manager.goos))
+		if entry.IsDir() && isAlreadyInstalled(execPath) {
+			installations = append(installations, Installation{Version: entry.Name(), ExecPath: execPath})
+		}
+	}
+	return installations, nil
+}
+
+func (manager *Manager) newFinder() assetFinder {
+	finder := newPlatformAssetFinder(manager.repository, manager.goos, manager.goarch).(*finder)
+	finder.minReleaseAge = manager.minReleaseAge
+	finder.previousMinor = manager.previousMinor
+	return finder
+}
+
+// resolveInstalled selects the highest installed version matching spec.
+func (manager *Manager) resolveInstalled(spec string) (*Version, error) {
+	desiredVersion, edition, err := extractEdition(spec)
+	if err != nil {
+		return nil, err
+	}
+	desiredVersion, constraint, err := splitConstraint(desiredVersion)
+	if err != nil {
+		return nil, err
+	}
+	matches := func(installed hugoversion.Version) bool { return true }
+	switch {
+	case desiredVersion == "latest":
+	case desiredVersion == LatestStable:
+		matches = constraint.Check
+	case strings.HasPrefix(desiredVersion, "@"):
+		// the publication dates of the releases are needed
+		matches = func(installed hugoversion.Version) bool { return false }
+	case hugoversion.IsConstraint(desiredVersion):
+		constraint, err := hugoversion.ParseConstraint(desiredVersion)
+		if err != nil {
+			return nil, &InvalidVersionSpecError{Spec: spec}
+		}
+		matches = constraint.Check
+	default:
+		desiredCore, precision, err := parseCoreVersion(desiredVersion)
+		if err != nil {
+			return nil, err
+		}
+		matches = func(installed hugoversion.Version) bool {
+			return desiredCore.Equal(&coreVersion{major: installed.Major, minor: installed.Minor, patch: installed.Patch}, precision)
+		}
+	}
+	installations, err := manager.Installations()
+	if err != nil {
+		return nil, err
+	}
+	var selectedVersion *Version
+	for _, installation := range installations {
+		installed, err := hugoversion.Parse(installation.Version)
+		if err != nil || installed.Edition != edition || !matches(installed) {
+			continue
+		}
+		installedCore := &coreVersion{major: installed.Major, minor: installed.Minor, patch: installed.Patch}
+		if selectedVersion == nil || installed.Compare(selectedVersion.Value()) > 0 {
+			selectedVersion = &Version{coreVersion: installedCore, finder: manager.newFinder()}
+			selectedVersion.setEdition(edition)
+		}
+	}
+	if selectedVersion == nil {
+		return nil, &OfflineError{Reason: fmt.Sprintf("no installed version matches %s and the network can't be used", spec)}
+	}
+	return selectedVersion, nil
+}
+
+func (manager *Manager) execPath(version *Version) string {
+	return path.Join(manager.installDirectory, version.String(), binaryName(manager.goos))
+}
+
+func (manager *Manager) install(ctx context.Context, execPath string, version *Version) (err error) {
+	client := manager.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	assetTmpFile, err := version.download(ctx, client)
+	if err != nil {
+		return err
+	}
 	defer os.Remove(assetTmpFile.Name())
+	assetTmpFile.Close()
+	if err = manager.verifyChecksum(ctx, client, version, assetTmpFile.Name()); err != nil {
+		return err
+	}
+	if err = os.RemoveAll(path.Dir(execPath)); err != nil {
+		return err
+	}
+	return archiver.Unarchive(assetTmpFile.Name(), path.Dir(execPath))
+}
+
+// verifyChecksum compares the downloaded asset with the checksums published in
+// the release, or with the digest of the asset when there are none.
+func (manager *Manager) verifyChecksum(ctx context.Context, client *http.Client, version *Version, assetPath string) error {
+	checksumsURL, err := version.finder.findChecksumsURL(ctx, version)
+	if errors.Is(err, ErrAssetNotFound) {
+		return manager.verifyDigest(ctx, version, assetPath)
+	}
 	if err != nil {
 		return err
 	}
-	err = archiver.Unarchive(assetTmpFile.Name(), path.Dir(execPath))
-	return
+	checksums, err := fetch(ctx, client, checksumsURL)
+	if err != nil {
+		return err
+	}
+	assetName, err := version.AssetName()
+	if err != nil {
+		return err
+	}
+	if err = verifyChecksum(assetPath, assetName, checksums); err != nil {
+		return err
+	}
+	manager.observer.OnEvent(Event{Kind: ChecksumVerified, Version: version.String(), Asset: assetName})
+	return nil
+}
+
+func (manager *Manager) verifyDigest(ctx context.Context, version *Version, assetPath string) error {
+	asset, err := version.finder.findAsset(ctx, version)
+	if err != nil {
+		return err
+	}
+	verified, err := verifyAssetDigest(asset, assetPath)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		manager.logger.Info("no checksums published, the download can't be verified")
+		return nil
+	}
+	manager.observer.OnEvent(Event{Kind: ChecksumVerified, Version: version.String(), Asset: asset.GetName()})
+	return nil
 }
 
 func isAlreadyInstalled(execPath string) bool {
@@ -65,8 +480,8 @@ func isAlreadyInstalled(execPath string) bool {
 	return err == nil
 }
 
-func binaryName() string {
-	if runtime.GOOS == "windows" {
+func binaryName(goos string) string {
+	if goos == "windows" {
 		return "hugo.exe"
 	} else {
 		return "hugo"
@@ -0,0 +1,288 @@
+package versionmanager
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestInstallDirectory(t *testing.T, versions ...string) string {
+	directory, err := ioutil.TempDir("", "hugo-wrapper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range versions {
+		versionDirectory := filepath.Join(directory, version)
+		if err := os.MkdirAll(versionDirectory, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(versionDirectory, binaryName(goOS())), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+func TestNew(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := New()
+	assert.NotNil(err, "the installation directory is mandatory")
+	_, err = New(WithInstallDirectory(filepath.Join(os.TempDir(), "does-not-exist")))
+	assert.NotNil(err, "the installation directory must exist")
+
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+	_, err = New(WithInstallDirectory(directory), WithPlatform("plan9", "amd64"))
+	assert.NotNil(err, "platforms without releases should be refused")
+}
+
+func TestResolveWithRepositoryClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	repository := NewMockRepositoryClient(ctrl)
+	release := NewMockRelease(ctrl)
+	repository.EXPECT().GetLatestRelease(gomock.Any()).Return(release, nil).Times(2)
+	release.EXPECT().GetName().Return("v0.72.3").Times(2)
+
+	manager, err := New(WithInstallDirectory(directory), WithRepositoryClient(repository), WithPlatform("windows", "386"))
+	assert.Nil(t, err)
+	version, err := manager.Resolve(context.Background(), "latest-extended")
+	assert.Nil(t, err)
+	assert.Equal(t, "v0.72.3-extended", version.String())
+	assetName, _ := version.AssetName()
+	assert.Equal(t, "hugo_extended_0.72.3_Windows-32bit.zip", assetName, "the asset of the selected platform should be used")
+	execPath, installed, err := manager.Which("latest-extended")
+	assert.Nil(t, err)
+	assert.False(t, installed)
+	assert.Equal(t, filepath.Join(directory, "v0.72.3-extended", "hugo.exe"), execPath)
+}
+
+func TestOfflineResolution(t *testing.T) {
+	assert := assert.New(t)
+	directory := newTestInstallDirectory(t, "v0.72.0", "v0.72.3", "v0.73.0-extended", "v0.71.1")
+	defer os.RemoveAll(directory)
+	manager, err := New(WithInstallDirectory(directory), WithCachePolicy(CacheOffline))
+	assert.Nil(err)
+	ctx := context.Background()
+
+	version, err := manager.Resolve(ctx, "0.72")
+	assert.Nil(err)
+	assert.Equal("v0.72.3", version.String())
+
+	version, err = manager.Resolve(ctx, "latest")
+	assert.Nil(err)
+	assert.Equal("v0.72.3", version.String(), "extended versions should not be selected for a standard spec")
+
+	version, err = manager.Resolve(ctx, "latest-extended")
+	assert.Nil(err)
+	assert.Equal("v0.73.0-extended", version.String())
+
+	_, err = manager.Resolve(ctx, "0.70")
+	assert.NotNil(err, "versions not installed can't be resolved offline")
+
+	execPath, err := manager.Install(ctx, version)
+	assert.Nil(err)
+	assert.Equal(filepath.Join(directory, "v0.73.0-extended", binaryName(goOS())), execPath)
+}
+
+func TestBisect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	repository := NewMockRepositoryClient(ctrl)
+	releases := []Release{}
+	for _, name := range []string{"v0.126.0", "v0.125.0", "v0.124.0-rc1", "v0.123.1", "v0.123.0", "v0.122.0", "v0.121.0", "v0.120.0"} {
+		release := NewMockRelease(ctrl)
+		release.EXPECT().GetName().Return(name).AnyTimes()
+		release.EXPECT().GetPrerelease().Return(name == "v0.124.0-rc1").AnyTimes()
+		release.EXPECT().GetDraft().Return(false).AnyTimes()
+		releases = append(releases, release)
+	}
+	repository.EXPECT().ListReleases(gomock.Any()).Return(releases, nil)
+	manager, err := New(WithInstallDirectory(directory), WithRepositoryClient(repository))
+	assert.Nil(err)
+
+	good := &Version{coreVersion: &coreVersion{major: 0, minor: 120, patch: 0}}
+	bad := &Version{coreVersion: &coreVersion{major: 0, minor: 125, patch: 0}, extended: true}
+	versions, err := manager.ReleasesBetween(ctx, good, bad)
+	assert.Nil(err)
+	names := []string{}
+	for _, version := range versions {
+		names = append(names, version.String())
+	}
+	assert.Equal([]string{"v0.121.0-extended", "v0.122.0-extended", "v0.123.0-extended", "v0.123.1-extended", "v0.125.0-extended"}, names)
+
+	checked := []string{}
+	firstBad, err := Bisect(versions, func(version *Version, remaining int) (bool, error) {
+		checked = append(checked, version.String())
+		return version.Value().Less(versions[2].Value()), nil
+	})
+	assert.Nil(err)
+	assert.Equal("v0.123.0-extended", firstBad.String())
+	assert.Equal([]string{"v0.122.0-extended", "v0.123.0-extended"}, checked)
+
+	_, err = manager.ReleasesBetween(ctx, bad, good)
+	assert.NotNil(err, "the good version must be older than the bad one")
+}
+
+func TestReleaseNotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	repository := NewMockRepositoryClient(ctrl)
+	releases := []Release{}
+	for _, name := range []string{"v0.122.0", "v0.121.1", "v0.121.0", "v0.120.0", "v0.113.0", "v0.112.1", "v0.112.0"} {
+		release := NewMockRelease(ctrl)
+		release.EXPECT().GetName().Return(name).AnyTimes()
+		release.EXPECT().GetPrerelease().Return(false).AnyTimes()
+		release.EXPECT().GetDraft().Return(false).AnyTimes()
+		release.EXPECT().GetPublishedAt().Return(time.Time{}).AnyTimes()
+		release.EXPECT().GetBody().Return("notes of " + name + "\n").AnyTimes()
+		releases = append(releases, release)
+	}
+	repository.EXPECT().ListReleases(gomock.Any()).Return(releases, nil)
+	manager, err := New(WithInstallDirectory(directory), WithRepositoryClient(repository))
+	assert.Nil(err)
+
+	versions := func(notes []ReleaseNote) []string {
+		names := []string{}
+		for _, note := range notes {
+			names = append(names, note.Version)
+		}
+		return names
+	}
+	notes, err := manager.ReleaseNotes(ctx, "0.112", "0.121")
+	assert.Nil(err)
+	assert.Equal([]string{"v0.112.1", "v0.113.0", "v0.120.0", "v0.121.0", "v0.121.1"}, versions(notes))
+	assert.Equal("notes of v0.112.1", notes[0].Body)
+	assert.Equal("https://github.com/gohugoio/hugo/releases/tag/v0.112.1", notes[0].URL())
+
+	notes, err = manager.ReleaseNotes(ctx, "0.120.0", "0.121.0")
+	assert.Nil(err, "the cached notes should be used")
+	assert.Equal([]string{"v0.121.0"}, versions(notes))
+	notes, err = manager.ReleaseNotes(ctx, "0.121.1", "latest")
+	assert.Nil(err)
+	assert.Equal([]string{"v0.122.0"}, versions(notes))
+
+	_, err = manager.ReleaseNotes(ctx, "0.121", "0.120")
+	assert.NotNil(err, "an empty range should be refused")
+}
+
+func TestOutdated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	repository := NewMockRepositoryClient(ctrl)
+	releases := []Release{}
+	for _, name := range []string{"v1.0.0", "v0.122.0", "v0.121.1", "v0.121.0", "v0.120.4", "v0.120.3", "v0.119.0"} {
+		release := NewMockRelease(ctrl)
+		release.EXPECT().GetName().Return(name).AnyTimes()
+		release.EXPECT().GetPrerelease().Return(false).AnyTimes()
+		release.EXPECT().GetDraft().Return(false).AnyTimes()
+		releases = append(releases, release)
+	}
+	repository.EXPECT().ListReleases(gomock.Any()).Return(releases, nil).Times(2)
+	manager, err := New(WithInstallDirectory(directory), WithRepositoryClient(repository))
+	assert.Nil(err)
+
+	version := &Version{coreVersion: &coreVersion{major: 0, minor: 120, patch: 3}, extended: true}
+	outdated, err := manager.CheckOutdated(ctx, version)
+	assert.Nil(err)
+	assert.Equal(&Outdated{Current: "v0.120.3", LatestPatch: "v0.120.4", LatestMinor: "v0.122.0", Latest: "v1.0.0", MinorsBehind: 3}, outdated)
+	assert.True(outdated.IsOutdated())
+
+	notice, err := manager.NewerPatchNotice(ctx, version)
+	assert.Nil(err)
+	assert.Equal("hugo v0.120.4 has been released in the v0.120 line, v0.120.3 is used", notice)
+	notice, err = manager.NewerPatchNotice(ctx, version)
+	assert.Nil(err)
+	assert.Equal("", notice, "the line should be checked once a day")
+}
+
+func TestFailedNotice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assert := assert.New(t)
+	ctx := context.Background()
+	directory := newTestInstallDirectory(t)
+	defer os.RemoveAll(directory)
+
+	repository := NewMockRepositoryClient(ctrl)
+	repository.EXPECT().ListReleases(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]Release, error) {
+		deadline, found := ctx.Deadline()
+		assert.True(found, "the check should have a deadline")
+		assert.True(time.Until(deadline) <= noticeTimeout)
+		return nil, &OfflineError{Reason: "the network is unreachable"}
+	}).Times(1)
+	manager, err := New(WithInstallDirectory(directory), WithRepositoryClient(repository))
+	assert.Nil(err)
+
+	version := &Version{coreVersion: &coreVersion{major: 0, minor: 120, patch: 3}}
+	_, err = manager.NewerPatchNotice(ctx, version)
+	assert.True(errors.Is(err, ErrOffline))
+	notice, err := manager.NewerPatchNotice(ctx, version)
+	assert.Nil(err)
+	assert.Equal("", notice, "a failed check should wait as long as a successful one")
+}
+
+func TestRetention(t *testing.T) {
+	assert := assert.New(t)
+	directory := newTestInstallDirectory(t, "v0.72.0", "v0.72.3", "v0.73.0-extended", "v0.71.1", "v0.70.0")
+	defer os.RemoveAll(directory)
+	var manager *Manager
+	var err error
+	installedVersions := func() []string {
+		installations, err := manager.Installations()
+		assert.Nil(err)
+		versions := []string{}
+		for _, installation := range installations {
+			versions = append(versions, installation.Version)
+		}
+		return versions
+	}
+	ctx := context.Background()
+	install := func(manager *Manager, spec string) {
+		version, err := manager.Resolve(ctx, spec)
+		assert.Nil(err)
+		_, err = manager.Install(ctx, version)
+		assert.Nil(err)
+	}
+
+	manager, err = New(WithInstallDirectory(directory), WithRetention(2), WithCachePolicy(CacheOffline))
+	assert.Nil(err)
+	for _, spec := range []string{"0.72.0", "0.72.3", "0.71.1"} {
+		install(manager, spec)
+	}
+	assert.Equal([]string{"v0.70.0", "v0.71.1", "v0.72.0", "v0.72.3", "v0.73.0-extended"}, installedVersions(), "nothing should be removed before the command has finished")
+	assert.Nil(manager.ApplyRetention("0.70"))
+	assert.Equal([]string{"v0.70.0", "v0.71.1", "v0.72.0", "v0.72.3"}, installedVersions(), "the versions used and the one pinned should be kept")
+
+	manager, err = New(WithInstallDirectory(directory), WithRetention(2), WithCachePolicy(CacheOffline))
+	assert.Nil(err)
+	install(manager, "0.71.1")
+	assert.Nil(manager.ApplyRetention())
+	assert.Equal([]string{"v0.71.1", "v0.72.3"}, installedVersions(), "the version used and the highest one should be kept")
+}